@@ -0,0 +1,135 @@
+package desec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// rawAPIError is returned for failed requests sent through apiRequest.
+type rawAPIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *rawAPIError) Error() string {
+	return fmt.Sprintf("%d: body: %s", e.StatusCode, string(e.Body))
+}
+
+// apiRequest sends a request to an API endpoint that the client library
+// doesn't cover, and returns the response body. The request body is JSON
+// encoded unless it is nil. Non-2xx responses are returned as *rawAPIError.
+func (conf *DesecConfig) apiRequest(ctx context.Context, method string, path []string, body interface{}) ([]byte, error) {
+	base, err := url.Parse(conf.client.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create endpoint: %w", err)
+	}
+	endpoint := base.JoinPath(path...)
+	endpoint.Path += "/"
+
+	buf := new(bytes.Buffer)
+	if body != nil {
+		err = json.NewEncoder(buf).Encode(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", conf.token))
+
+	resp, err := conf.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call API: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &rawAPIError{StatusCode: resp.StatusCode, Body: respBody}
+	}
+
+	return respBody, nil
+}
+
+// apiErrorDiagnostics turns the field errors of a rejected request into one
+// diagnostic per message, pointing at the attribute of the same name. The
+// API reports them as {"field": ["message", ...]}, possibly nested.
+func apiErrorDiagnostics(err error) diag.Diagnostics {
+	rawError, ok := err.(*rawAPIError)
+	if !ok || rawError.StatusCode != http.StatusBadRequest {
+		return diag.FromErr(err)
+	}
+
+	var fields map[string]interface{}
+	if json.Unmarshal(rawError.Body, &fields) != nil || len(fields) == 0 {
+		return diag.FromErr(err)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var diags diag.Diagnostics
+	for _, k := range keys {
+		for _, message := range apiErrorMessages(fields[k]) {
+			d := diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%s rejected by the API", k),
+				Detail:   message,
+			}
+			if k != "non_field_errors" && k != "detail" {
+				d.AttributePath = cty.GetAttrPath(k)
+			}
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+func apiErrorMessages(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, e := range v {
+			result = append(result, apiErrorMessages(e)...)
+		}
+		return result
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var result []string
+		for _, k := range keys {
+			for _, message := range apiErrorMessages(v[k]) {
+				result = append(result, fmt.Sprintf("%s: %s", k, message))
+			}
+		}
+		return result
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,26 @@
+package desec
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestAPIErrorDiagnostics(t *testing.T) {
+	err := &rawAPIError{
+		StatusCode: http.StatusBadRequest,
+		Body:       []byte(`{"zonefile": ["line 3: unknown rdatatype", "line 7: bad TTL"], "non_field_errors": ["domain exists"]}`),
+	}
+
+	diags := apiErrorDiagnostics(err)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %v", diags)
+	}
+	if diags[0].AttributePath != nil || diags[0].Detail != "domain exists" {
+		t.Errorf("unexpected diagnostic %v", diags[0])
+	}
+	if !diags[1].AttributePath.Equals(cty.GetAttrPath("zonefile")) || diags[1].Detail != "line 3: unknown rdatatype" {
+		t.Errorf("unexpected diagnostic %v", diags[1])
+	}
+}
@@ -0,0 +1,83 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// accountInfo is the account as returned by the API. The client's
+// Account.RetrieveInformation sends a POST, which the endpoint doesn't accept.
+type accountInfo struct {
+	ID                 string    `json:"id"`
+	Email              string    `json:"email"`
+	Created            time.Time `json:"created"`
+	LimitDomains       *int      `json:"limit_domains"`
+	OutreachPreference bool      `json:"outreach_preference"`
+}
+
+func dataSourceAccount() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceAccountRead,
+		Schema: map[string]*schema.Schema{
+			"email": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"limit_domains": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"domains_used": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceAccountRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	account, err := getAccount(ctx, conf)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	domains, err := getAllDomains(ctx, c)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(account.ID)
+	d.Set("email", account.Email)
+	d.Set("created", account.Created.Format(time.RFC3339))
+	if account.LimitDomains != nil {
+		d.Set("limit_domains", *account.LimitDomains)
+	}
+	d.Set("domains_used", len(domains))
+	return nil
+}
+
+func getAccount(ctx context.Context, conf *DesecConfig) (*accountInfo, error) {
+	body, err := conf.apiRequest(ctx, http.MethodGet, []string{"auth", "account"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var account accountInfo
+	err = json.Unmarshal(body, &account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return &account, nil
+}
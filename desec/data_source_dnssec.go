@@ -0,0 +1,220 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Digest types used when digest_types is not set, as published by deSEC.
+var defaultDSDigestTypes = []int{2, 4}
+
+func dataSourceDNSSEC() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDNSSECRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"digest_types": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntInSlice([]int{1, 2, 4}),
+				},
+			},
+			"keys": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key_tag": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"flags": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"protocol": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"algorithm": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"public_key": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dnskey": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"cdnskey": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ds": dsRecordsSchema(),
+					},
+				},
+			},
+			"ds_records": dsRecordsSchema(),
+			"cds": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"cdnskey": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dsRecordsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"key_tag": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"algorithm": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"digest_type": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"digest": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"ds": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"cds": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDNSSECRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName := d.Get("domain").(string)
+	domain, err := c.Domains.Get(ctx, domainName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("domain %q not found", domainName)
+		}
+		return diag.FromErr(err)
+	}
+
+	digestTypes := defaultDSDigestTypes
+	if raw := d.Get("digest_types").([]interface{}); len(raw) > 0 {
+		digestTypes = make([]int, len(raw))
+		for i, t := range raw {
+			digestTypes[i] = t.(int)
+		}
+	}
+
+	owner := domain.Name + "."
+	keys := make([]interface{}, 0, len(domain.Keys))
+	dsRecords := make([]interface{}, 0)
+	cds := make([]string, 0)
+	cdnskeys := make([]string, 0)
+	for _, domainKey := range domain.Keys {
+		k, err := parseDNSKey(domainKey.DNSKey)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		keyTag, err := k.KeyTag()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		// published DS records are only used to check the local computation
+		published := make(map[int]string)
+		for _, s := range domainKey.DS {
+			ds, err := parseDS(s)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			published[ds.DigestType] = ds.Digest
+		}
+
+		keyDS := make([]interface{}, 0, len(digestTypes))
+		// only keys with the SEP flag are referenced from the parent
+		if k.Flags&1 == 1 {
+			for _, digestType := range digestTypes {
+				ds, err := k.DS(owner, digestType)
+				if err != nil {
+					return diag.FromErr(err)
+				}
+				if digest, ok := published[digestType]; ok && digest != ds.Digest {
+					return diag.Errorf("computed DS %q for key %d differs from the one published by deSEC (%s)", ds, keyTag, digest)
+				}
+				flat := flattenDSRecord(owner, ds)
+				keyDS = append(keyDS, flat)
+				dsRecords = append(dsRecords, flat)
+				cds = append(cds, flat["cds"].(string))
+			}
+		}
+
+		cdnskey := ""
+		if k.Flags&1 == 1 {
+			cdnskey = fmt.Sprintf("%s IN CDNSKEY %s", owner, k)
+			cdnskeys = append(cdnskeys, cdnskey)
+		}
+		keys = append(keys, map[string]interface{}{
+			"key_tag":    keyTag,
+			"flags":      k.Flags,
+			"protocol":   k.Protocol,
+			"algorithm":  k.Algorithm,
+			"public_key": k.PublicKey,
+			"dnskey":     k.String(),
+			"cdnskey":    cdnskey,
+			"ds":         keyDS,
+		})
+	}
+
+	d.SetId(domain.Name)
+	d.Set("keys", keys)
+	d.Set("ds_records", dsRecords)
+	d.Set("cds", cds)
+	d.Set("cdnskey", cdnskeys)
+	return nil
+}
+
+func flattenDSRecord(owner string, ds dsRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"key_tag":     ds.KeyTag,
+		"algorithm":   ds.Algorithm,
+		"digest_type": ds.DigestType,
+		"digest":      ds.Digest,
+		"ds":          ds.String(),
+		"cds":         fmt.Sprintf("%s IN CDS %s", owner, ds),
+	}
+}
@@ -0,0 +1,52 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestDNSSECDataSourceSkipsNonSEPKeys(t *testing.T) {
+	sepKey := "257" + strings.TrimPrefix(testDNSKey, "256")
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(dsc.Domain{
+			Name: "dskey.example.com",
+			Keys: []dsc.DomainKey{
+				{DNSKey: testDNSKey},
+				{DNSKey: sepKey},
+			},
+		})
+	}))
+	conf := &DesecConfig{client: c}
+
+	d := schema.TestResourceDataRaw(t, dataSourceDNSSEC().Schema, map[string]interface{}{
+		"domain":       "dskey.example.com",
+		"digest_types": []interface{}{2},
+	})
+	if diags := dataSourceDNSSECRead(context.Background(), d, conf); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	keys := d.Get("keys").([]interface{})
+	if len(keys) != 2 {
+		t.Fatalf("expected both keys, got %v", keys)
+	}
+	nonSEP := keys[0].(map[string]interface{})
+	if nonSEP["cdnskey"] != "" || len(nonSEP["ds"].([]interface{})) != 0 {
+		t.Errorf("expected no CDNSKEY or DS for the key without the SEP flag, got %v", nonSEP)
+	}
+
+	cdnskeys := d.Get("cdnskey").([]interface{})
+	if len(cdnskeys) != 1 || !strings.HasPrefix(cdnskeys[0].(string), "dskey.example.com. IN CDNSKEY 257 3 5 ") {
+		t.Errorf("expected only the CDNSKEY of the key with the SEP flag, got %v", cdnskeys)
+	}
+	if cds := d.Get("cds").([]interface{}); len(cds) != 1 {
+		t.Errorf("expected one CDS, got %v", cds)
+	}
+}
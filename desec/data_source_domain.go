@@ -0,0 +1,50 @@
+package desec
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDomain() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDomainRead,
+		Schema: map[string]*schema.Schema{
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"minimum_ttl": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"published": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"keys": domainKeysSchema(),
+		},
+	}
+}
+
+func dataSourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName := d.Get("name").(string)
+	domain, err := c.Domains.Get(ctx, domainName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("domain %q not found", domainName)
+		}
+		return diag.FromErr(err)
+	}
+
+	domainIntoData(domain, d)
+	return nil
+}
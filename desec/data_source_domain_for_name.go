@@ -0,0 +1,71 @@
+package desec
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func dataSourceDomainForName() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDomainForNameRead,
+		Schema: map[string]*schema.Schema{
+			"qname": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// optional, to build the id of an RRset at qname
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"domain": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"subname": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"rrset_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceDomainForNameRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	qname := strings.ToLower(strings.TrimSuffix(d.Get("qname").(string), "."))
+	domain, err := c.Domains.GetResponsible(ctx, qname)
+	if err != nil {
+		var notFound *dsc.NotFoundError
+		if errors.As(err, &notFound) {
+			return diag.Errorf("no domain of this account is responsible for %q", qname)
+		}
+		return diag.FromErr(err)
+	}
+
+	subName, err := subNameFromQName(qname, domain.Name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("domain", domain.Name)
+	d.Set("subname", subName)
+	if recordType := d.Get("type").(string); recordType != "" {
+		d.Set("rrset_id", idFromNames(domain.Name, subName, recordType))
+	} else {
+		d.Set("rrset_id", "")
+	}
+	d.SetId(qname)
+	return nil
+}
@@ -0,0 +1,79 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestSubNameFromQName(t *testing.T) {
+	tests := []struct {
+		qname   string
+		domain  string
+		subName string
+		fails   bool
+	}{
+		{qname: "example.com", domain: "example.com", subName: ""},
+		{qname: "example.com.", domain: "example.com", subName: ""},
+		{qname: "example.com", domain: "example.com.", subName: ""},
+		{qname: "www.example.com", domain: "example.com", subName: "www"},
+		{qname: "a.b.c.example.com.", domain: "example.com", subName: "a.b.c"},
+		{qname: "WWW.Example.COM", domain: "example.com", subName: "www"},
+		{qname: "www.example.com", domain: "EXAMPLE.com.", subName: "www"},
+		{qname: "_acme-challenge.www.example.com", domain: "example.com", subName: "_acme-challenge.www"},
+		{qname: "example.org", domain: "example.com", fails: true},
+		{qname: "notexample.com", domain: "example.com", fails: true},
+		{qname: "com", domain: "example.com", fails: true},
+	}
+
+	for _, test := range tests {
+		subName, err := subNameFromQName(test.qname, test.domain)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%q in %q: expected an error, got %q", test.qname, test.domain, subName)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q in %q: %v", test.qname, test.domain, err)
+			continue
+		}
+		if subName != test.subName {
+			t.Errorf("%q in %q: got %q, want %q", test.qname, test.domain, subName, test.subName)
+		}
+	}
+}
+
+func TestDomainForNameRead(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		domains := []dsc.Domain{}
+		if req.URL.Query().Get("owns_qname") == "www.sub.example.com" {
+			domains = append(domains, dsc.Domain{Name: "sub.example.com"})
+		}
+		json.NewEncoder(w).Encode(domains)
+	}))
+	conf := &DesecConfig{client: c}
+
+	d := schema.TestResourceDataRaw(t, dataSourceDomainForName().Schema, map[string]interface{}{
+		"qname": "WWW.sub.example.com.",
+		"type":  "A",
+	})
+	if diags := dataSourceDomainForNameRead(context.Background(), d, conf); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if d.Get("domain") != "sub.example.com" || d.Get("subname") != "www" || d.Get("rrset_id") != "sub.example.com/www/A" {
+		t.Errorf("got domain %q, subname %q, rrset_id %q", d.Get("domain"), d.Get("subname"), d.Get("rrset_id"))
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceDomainForName().Schema, map[string]interface{}{
+		"qname": "www.example.org",
+	})
+	if diags := dataSourceDomainForNameRead(context.Background(), d, conf); !diags.HasError() {
+		t.Errorf("expected an error for a name outside of the account")
+	}
+}
@@ -0,0 +1,45 @@
+package desec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDesecDomainDataSource(t *testing.T) {
+	uuid, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	domainName := fmt.Sprintf("%s.example", uuid)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDesecDomainDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDesecDomainDataSourceConfig(domainName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.desec_domain.desec-example", "name", domainName),
+					resource.TestCheckResourceAttrPair("data.desec_domain.desec-example", "minimum_ttl", "desec_domain.desec-example", "minimum_ttl"),
+					resource.TestCheckResourceAttrPair("data.desec_domain.desec-example", "keys.#", "desec_domain.desec-example", "keys.#"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDesecDomainDataSourceConfig(domainName string) string {
+	return fmt.Sprintf(`
+	resource "desec_domain" "desec-example" {
+		name = "%s"
+	}
+	data "desec_domain" "desec-example" {
+		name = desec_domain.desec-example.name
+	}
+	`, domainName)
+}
@@ -0,0 +1,116 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceDomains() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDomainsRead,
+		Schema: map[string]*schema.Schema{
+			"name_suffix": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"published_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"names": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"domains": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"created": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"minimum_ttl": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"published": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceDomainsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	suffix := d.Get("name_suffix").(string)
+
+	var nameRegex *regexp.Regexp
+	if v, ok := d.GetOk("name_regex"); ok {
+		nameRegex = regexp.MustCompile(v.(string))
+	}
+
+	var publishedAfter time.Time
+	if v, ok := d.GetOk("published_after"); ok {
+		publishedAfter, _ = time.Parse(time.RFC3339, v.(string))
+	}
+
+	domains, err := getAllDomains(ctx, c)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].Name < domains[j].Name
+	})
+
+	names := []string{}
+	flattened := []interface{}{}
+	for _, domain := range domains {
+		if suffix != "" && !strings.HasSuffix(domain.Name, suffix) {
+			continue
+		}
+		if nameRegex != nil && !nameRegex.MatchString(domain.Name) {
+			continue
+		}
+		if !publishedAfter.IsZero() && (domain.Published == nil || !domain.Published.After(publishedAfter)) {
+			continue
+		}
+		names = append(names, domain.Name)
+		// the list endpoint doesn't return keys
+		flat := flattenDomain(&domain)
+		delete(flat, "keys")
+		flattened = append(flattened, flat)
+	}
+
+	d.Set("names", names)
+	d.Set("domains", flattened)
+	d.SetId(fmt.Sprintf("%d", schema.HashString(strings.Join(names, ","))))
+	return nil
+}
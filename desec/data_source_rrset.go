@@ -0,0 +1,85 @@
+package desec
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRRSet() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRRSetRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"subname": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			// if set, a missing RRset yields exists = false instead of an error
+			"allow_missing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"exists": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"created": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"records": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"ttl": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRRSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	id := idFromNames(d.Get("domain").(string), d.Get("subname").(string), d.Get("type").(string))
+	r, err := conf.cache.GetRRSetById(ctx, c, id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if r == nil {
+		if !d.Get("allow_missing").(bool) {
+			return diag.Errorf("RRset %q not found", id)
+		}
+		d.Set("exists", false)
+		d.Set("created", "")
+		d.Set("name", "")
+		d.Set("records", []string{})
+		d.Set("ttl", 0)
+		d.SetId(id)
+		return nil
+	}
+
+	d.Set("exists", true)
+	rrsetIntoSchema(r, d)
+	return nil
+}
@@ -0,0 +1,78 @@
+package desec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestRRSetDataSourceRead(t *testing.T) {
+	tests := []struct {
+		subName      string
+		allowMissing bool
+		fails        bool
+		exists       bool
+	}{
+		{subName: "host1", exists: true},
+		{subName: "host1", allowMissing: true, exists: true},
+		{subName: "missing", fails: true},
+		{subName: "missing", allowMissing: true, exists: false},
+	}
+
+	for _, test := range tests {
+		cache := NewDesecCache()
+		conf := &DesecConfig{cache: &cache, client: newTestClient(t, pagedRRSets(3, 500, ""))}
+
+		d := schema.TestResourceDataRaw(t, dataSourceRRSet().Schema, map[string]interface{}{
+			"domain":        "example.com",
+			"subname":       test.subName,
+			"type":          "A",
+			"allow_missing": test.allowMissing,
+		})
+		diags := dataSourceRRSetRead(context.Background(), d, conf)
+		if test.fails {
+			if !diags.HasError() {
+				t.Errorf("%s, allow_missing %t: expected an error", test.subName, test.allowMissing)
+			}
+			continue
+		}
+		if diags.HasError() {
+			t.Errorf("%s, allow_missing %t: %v", test.subName, test.allowMissing, diags)
+			continue
+		}
+
+		if d.Get("exists").(bool) != test.exists {
+			t.Errorf("%s, allow_missing %t: got exists %t", test.subName, test.allowMissing, d.Get("exists"))
+		}
+		if d.Id() != "example.com/"+test.subName+"/A" {
+			t.Errorf("%s, allow_missing %t: got id %q", test.subName, test.allowMissing, d.Id())
+		}
+		records := d.Get("records").(*schema.Set)
+		if test.exists && (records.Len() != 1 || !records.Contains("127.0.0.1")) {
+			t.Errorf("%s: got records %v", test.subName, records.List())
+		}
+		if !test.exists && (records.Len() != 0 || d.Get("ttl").(int) != 0) {
+			t.Errorf("%s: missing RRset has records %v, ttl %d", test.subName, records.List(), d.Get("ttl"))
+		}
+	}
+}
+
+func TestRRSetDataSourceMissingDomain(t *testing.T) {
+	cache := NewDesecCache()
+	conf := &DesecConfig{cache: &cache, client: newTestClient(t, pagedRRSets(3, 500, ""))}
+
+	// a domain that doesn't exist is treated like a missing RRset
+	d := schema.TestResourceDataRaw(t, dataSourceRRSet().Schema, map[string]interface{}{
+		"domain":        "example.org",
+		"subname":       "host1",
+		"type":          "A",
+		"allow_missing": true,
+	})
+	if diags := dataSourceRRSetRead(context.Background(), d, conf); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if d.Get("exists").(bool) {
+		t.Errorf("expected exists to be false")
+	}
+}
@@ -0,0 +1,205 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func dataSourceRRSets() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRRSetsRead,
+		Schema: map[string]*schema.Schema{
+			// empty means all domains of the account
+			"domains": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"subname": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"subname_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"record_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"rrsets": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"records": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ttl": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRRSetsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var domainNames []string
+	for _, v := range d.Get("domains").([]interface{}) {
+		domainNames = append(domainNames, v.(string))
+	}
+	if len(domainNames) == 0 {
+		domains, err := getAllDomains(ctx, c)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		for _, domain := range domains {
+			domainNames = append(domainNames, domain.Name)
+		}
+	}
+	sort.Strings(domainNames)
+
+	filter := dsc.RRSetFilter{Type: dsc.IgnoreFilter, SubName: dsc.IgnoreFilter}
+	if v := d.Get("type").(string); v != "" {
+		filter.Type = v
+	}
+	if v := d.Get("subname").(string); v != "" {
+		// empty means no filter, so the apex is selected as "@"
+		if v == "@" {
+			v = ""
+		}
+		filter.SubName = v
+	}
+
+	var subNameRegex, recordRegex *regexp.Regexp
+	if v, ok := d.GetOk("subname_regex"); ok {
+		subNameRegex = regexp.MustCompile(v.(string))
+	}
+	if v, ok := d.GetOk("record_regex"); ok {
+		recordRegex = regexp.MustCompile(v.(string))
+	}
+
+	ids := []string{}
+	result := []interface{}{}
+	for _, domainName := range domainNames {
+		rrsets, err := listRRSets(ctx, conf, domainName, filter)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		for _, r := range rrsets {
+			if filter.Type != dsc.IgnoreFilter && r.Type != filter.Type {
+				continue
+			}
+			if filter.SubName != dsc.IgnoreFilter && r.SubName != filter.SubName {
+				continue
+			}
+			if subNameRegex != nil && !subNameRegex.MatchString(r.SubName) {
+				continue
+			}
+			records := normalizeRecordSet(r.Records)
+			if recordRegex != nil && !anyMatch(recordRegex, records) {
+				continue
+			}
+
+			id := idFromNames(r.Domain, r.SubName, r.Type)
+			ids = append(ids, id)
+			result = append(result, map[string]interface{}{
+				"id":      id,
+				"domain":  r.Domain,
+				"subname": r.SubName,
+				"type":    r.Type,
+				"name":    r.Name,
+				"records": records,
+				"ttl":     r.TTL,
+			})
+		}
+	}
+
+	d.Set("rrsets", result)
+	d.SetId(fmt.Sprintf("%d", schema.HashString(strings.Join(ids, ","))))
+	return nil
+}
+
+// listRRSets lists the RRsets of a domain. Type and subname are filtered by
+// the API where given, everything else comes from the cache.
+func listRRSets(ctx context.Context, conf *DesecConfig, domainName string, filter dsc.RRSetFilter) ([]dsc.RRSet, error) {
+	if filter.Type == dsc.IgnoreFilter && filter.SubName == dsc.IgnoreFilter {
+		rrsets, err := conf.cache.GetRRSetsByDomain(ctx, conf.client, domainName)
+		if err != nil {
+			return nil, err
+		}
+		if rrsets == nil {
+			return nil, fmt.Errorf("domain %q not found", domainName)
+		}
+		return rrsets, nil
+	}
+
+	rrsets, err := getAllRRSets(ctx, conf.client, domainName, &filter)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, fmt.Errorf("domain %q not found", domainName)
+		}
+		return nil, err
+	}
+	sort.Slice(rrsets, func(i, j int) bool {
+		return idFromNames(rrsets[i].Domain, rrsets[i].SubName, rrsets[i].Type) < idFromNames(rrsets[j].Domain, rrsets[j].SubName, rrsets[j].Type)
+	})
+	return rrsets, nil
+}
+
+func anyMatch(re *regexp.Regexp, s []string) bool {
+	for _, v := range s {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
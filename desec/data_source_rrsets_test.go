@@ -0,0 +1,112 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// filteredRRSets serves a few RRsets of example.com and applies the type and
+// subname filters the way the API does.
+func filteredRRSets() http.Handler {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	all := []dsc.RRSet{
+		{Domain: "example.com", SubName: "", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600, Created: &created},
+		{Domain: "example.com", SubName: "", Type: "TXT", Records: []string{"\"apex\""}, TTL: 3600, Created: &created},
+		{Domain: "example.com", SubName: "www", Type: "A", Records: []string{"127.0.0.2"}, TTL: 3600, Created: &created},
+		{Domain: "example.com", SubName: "www", Type: "TXT", Records: []string{"\"www\""}, TTL: 3600, Created: &created},
+		{Domain: "example.com", SubName: "mail", Type: "A", Records: []string{"127.0.0.3"}, TTL: 3600, Created: &created},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/domains/example.com/rrsets/" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Not found."}`))
+			return
+		}
+
+		query := req.URL.Query()
+		result := []dsc.RRSet{}
+		for _, r := range all {
+			if query.Has("type") && r.Type != query.Get("type") {
+				continue
+			}
+			if query.Has("subname") && r.SubName != query.Get("subname") {
+				continue
+			}
+			result = append(result, r)
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+func TestRRSetsDataSourceFilters(t *testing.T) {
+	tests := []struct {
+		args map[string]interface{}
+		ids  []string
+	}{
+		{
+			args: map[string]interface{}{},
+			ids:  []string{"example.com/@/A", "example.com/@/TXT", "example.com/mail/A", "example.com/www/A", "example.com/www/TXT"},
+		},
+		{
+			args: map[string]interface{}{"type": "A"},
+			ids:  []string{"example.com/@/A", "example.com/mail/A", "example.com/www/A"},
+		},
+		{
+			args: map[string]interface{}{"subname": "www"},
+			ids:  []string{"example.com/www/A", "example.com/www/TXT"},
+		},
+		{
+			args: map[string]interface{}{"subname": "@"},
+			ids:  []string{"example.com/@/A", "example.com/@/TXT"},
+		},
+		{
+			args: map[string]interface{}{"subname": "@", "type": "TXT"},
+			ids:  []string{"example.com/@/TXT"},
+		},
+		{
+			args: map[string]interface{}{"type": "A", "subname_regex": "^(www|mail)$"},
+			ids:  []string{"example.com/mail/A", "example.com/www/A"},
+		},
+		{
+			args: map[string]interface{}{"record_regex": "^127\\.0\\.0\\.[12]$"},
+			ids:  []string{"example.com/@/A", "example.com/www/A"},
+		},
+		{
+			args: map[string]interface{}{"subname": "@", "record_regex": "^apex$"},
+			ids:  []string{"example.com/@/TXT"},
+		},
+		{
+			args: map[string]interface{}{"subname": "nothing"},
+			ids:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		cache := NewDesecCache()
+		conf := &DesecConfig{cache: &cache, client: newTestClient(t, filteredRRSets())}
+
+		test.args["domains"] = []interface{}{"example.com"}
+		d := schema.TestResourceDataRaw(t, dataSourceRRSets().Schema, test.args)
+		if diags := dataSourceRRSetsRead(context.Background(), d, conf); diags.HasError() {
+			t.Errorf("%v: %v", test.args, diags)
+			continue
+		}
+
+		ids := []string{}
+		for _, r := range d.Get("rrsets").([]interface{}) {
+			ids = append(ids, r.(map[string]interface{})["id"].(string))
+		}
+		if !reflect.DeepEqual(ids, test.ids) {
+			t.Errorf("%v: got %v, want %v", test.args, ids, test.ids)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package desec
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func dataSourceZoneExport() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceZoneExportRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"bind": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"json": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"octodns_yaml": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceZoneExportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName := d.Get("domain").(string)
+	rrsets, err := conf.cache.GetRRSetsByDomain(ctx, c, domainName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if rrsets == nil {
+		return diag.Errorf("domain %q not found", domainName)
+	}
+
+	normalized := make([]dsc.RRSet, len(rrsets))
+	for i, r := range rrsets {
+		r.Records = normalizeRecordSet(r.Records)
+		normalized[i] = r
+	}
+
+	zoneJSON, err := renderZoneJSON(domainName, normalized)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("bind", renderZoneBIND(domainName, normalized))
+	d.Set("json", zoneJSON)
+	d.Set("octodns_yaml", renderZoneOctoDNS(normalized))
+	d.SetId(domainName)
+	return nil
+}
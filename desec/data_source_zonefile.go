@@ -0,0 +1,84 @@
+package desec
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var dnssecRecordTypes = map[string]bool{
+	"DNSKEY":     true,
+	"CDS":        true,
+	"CDNSKEY":    true,
+	"RRSIG":      true,
+	"NSEC":       true,
+	"NSEC3":      true,
+	"NSEC3PARAM": true,
+}
+
+func dataSourceZonefile() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceZonefileRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"exclude_soa": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			// only the apex NS, delegations are kept
+			"exclude_ns": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"exclude_dnssec": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"zonefile": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceZonefileRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	domainName := d.Get("domain").(string)
+	zonefile, err := getDomainZonefile(ctx, conf, domainName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("domain %q not found", domainName)
+		}
+		return diag.FromErr(err)
+	}
+
+	excludeSOA := d.Get("exclude_soa").(bool)
+	excludeNS := d.Get("exclude_ns").(bool)
+	excludeDNSSEC := d.Get("exclude_dnssec").(bool)
+	zonefile = filterZonefile(zonefile, func(owner, recordType string) bool {
+		apex := owner == "@" || strings.EqualFold(strings.TrimSuffix(owner, "."), domainName)
+		switch {
+		case excludeSOA && recordType == "SOA":
+			return true
+		case excludeNS && apex && recordType == "NS":
+			return true
+		case excludeDNSSEC && dnssecRecordTypes[recordType]:
+			return true
+		}
+		return false
+	})
+
+	d.Set("zonefile", zonefile)
+	d.SetId(domainName)
+	return nil
+}
@@ -0,0 +1,94 @@
+package desec
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+/* Implementation notes:
+ *  - Parsing happens locally, this data source makes no API requests
+ *  - rrsets would ideally be a map keyed by id, but the SDK only supports maps of primitive values
+ */
+func dataSourceZonefileRRSets() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceZonefileRRSetsRead,
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"content": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"default_ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntBetween(60, 604800),
+			},
+			"rrsets": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"domain": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"subname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"records": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ttl": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceZonefileRRSetsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	domainName := d.Get("domain").(string)
+	rrsets, err := parseZonefile(d.Get("content").(string), domainName, d.Get("default_ttl").(int))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	result := make([]interface{}, 0, len(rrsets))
+	for _, r := range sortedRRSets(rrsets) {
+		result = append(result, map[string]interface{}{
+			"id":      idFromNames(r.Domain, r.SubName, r.Type),
+			"domain":  r.Domain,
+			"subname": r.SubName,
+			"type":    r.Type,
+			"records": r.Records,
+			"ttl":     r.TTL,
+		})
+	}
+
+	d.Set("rrsets", result)
+	d.SetId(domainName)
+	return nil
+}
@@ -0,0 +1,162 @@
+package desec
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// DS digest types that can be computed locally.
+// https://www.iana.org/assignments/ds-rr-types/ds-rr-types.xhtml
+var dsDigests = map[int]func() hash.Hash{
+	1: sha1.New,
+	2: sha256.New,
+	4: sha512.New384,
+}
+
+type dnskey struct {
+	Flags     int
+	Protocol  int
+	Algorithm int
+	PublicKey string
+}
+
+type dsRecord struct {
+	KeyTag     int
+	Algorithm  int
+	DigestType int
+	Digest     string
+}
+
+func (ds dsRecord) String() string {
+	return fmt.Sprintf("%d %d %d %s", ds.KeyTag, ds.Algorithm, ds.DigestType, ds.Digest)
+}
+
+// parseDNSKey parses DNSKEY content in presentation format, such as
+// "257 3 13 base64...". The key may be split by blanks.
+func parseDNSKey(s string) (dnskey, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return dnskey{}, fmt.Errorf("invalid DNSKEY %q", s)
+	}
+
+	var k dnskey
+	var err error
+	if k.Flags, err = strconv.Atoi(fields[0]); err != nil {
+		return dnskey{}, fmt.Errorf("invalid DNSKEY flags %q", fields[0])
+	}
+	if k.Protocol, err = strconv.Atoi(fields[1]); err != nil {
+		return dnskey{}, fmt.Errorf("invalid DNSKEY protocol %q", fields[1])
+	}
+	if k.Algorithm, err = strconv.Atoi(fields[2]); err != nil {
+		return dnskey{}, fmt.Errorf("invalid DNSKEY algorithm %q", fields[2])
+	}
+	k.PublicKey = strings.Join(fields[3:], "")
+	return k, nil
+}
+
+// parseDS parses DS content in presentation format.
+func parseDS(s string) (dsRecord, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 4 {
+		return dsRecord{}, fmt.Errorf("invalid DS %q", s)
+	}
+
+	var ds dsRecord
+	var err error
+	if ds.KeyTag, err = strconv.Atoi(fields[0]); err != nil {
+		return dsRecord{}, fmt.Errorf("invalid DS key tag %q", fields[0])
+	}
+	if ds.Algorithm, err = strconv.Atoi(fields[1]); err != nil {
+		return dsRecord{}, fmt.Errorf("invalid DS algorithm %q", fields[1])
+	}
+	if ds.DigestType, err = strconv.Atoi(fields[2]); err != nil {
+		return dsRecord{}, fmt.Errorf("invalid DS digest type %q", fields[2])
+	}
+	ds.Digest = strings.ToLower(strings.Join(fields[3:], ""))
+	return ds, nil
+}
+
+func (k dnskey) String() string {
+	return fmt.Sprintf("%d %d %d %s", k.Flags, k.Protocol, k.Algorithm, k.PublicKey)
+}
+
+// rdata returns the key in wire format.
+func (k dnskey) rdata() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(k.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DNSKEY public key: %w", err)
+	}
+	result := make([]byte, 4, 4+len(key))
+	binary.BigEndian.PutUint16(result, uint16(k.Flags))
+	result[2] = byte(k.Protocol)
+	result[3] = byte(k.Algorithm)
+	return append(result, key...), nil
+}
+
+// KeyTag computes the key tag as in RFC 4034, Appendix B.
+func (k dnskey) KeyTag() (int, error) {
+	rdata, err := k.rdata()
+	if err != nil {
+		return 0, err
+	}
+
+	var ac uint32
+	for i, b := range rdata {
+		if i&1 == 1 {
+			ac += uint32(b)
+		} else {
+			ac += uint32(b) << 8
+		}
+	}
+	ac += (ac >> 16) & 0xFFFF
+	return int(ac & 0xFFFF), nil
+}
+
+// DS computes the DS record of the key for the given owner name, as in RFC
+// 4034, Section 5.1.4.
+func (k dnskey) DS(owner string, digestType int) (dsRecord, error) {
+	newHash, ok := dsDigests[digestType]
+	if !ok {
+		return dsRecord{}, fmt.Errorf("unsupported DS digest type %d", digestType)
+	}
+
+	rdata, err := k.rdata()
+	if err != nil {
+		return dsRecord{}, err
+	}
+	keyTag, err := k.KeyTag()
+	if err != nil {
+		return dsRecord{}, err
+	}
+
+	h := newHash()
+	h.Write(nameToWire(owner))
+	h.Write(rdata)
+	return dsRecord{
+		KeyTag:     keyTag,
+		Algorithm:  k.Algorithm,
+		DigestType: digestType,
+		Digest:     hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// nameToWire encodes a domain name in canonical wire format.
+func nameToWire(name string) []byte {
+	var result []byte
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			result = append(result, byte(len(label)))
+			result = append(result, label...)
+		}
+	}
+	return append(result, 0)
+}
@@ -0,0 +1,55 @@
+package desec
+
+import (
+	"testing"
+)
+
+// from RFC 4034, Section 5.4, and RFC 4509, Section 2.2
+const testDNSKey = "256 3 5 AQOeiiR0GOMYkDshWoSKz9Xz fwJr1AYtsmx3TGkJaNXVbfi/ 2pHm822aJ5iI9BMzNXxeYCmZ " +
+	"DRD99WYwYqUSdjMmmAphXdvx egXd/M5+X7OrzKBaMbCVdFLU Uh6DhweJBjEVv5f2wwjM9Xzc nOf+EPbtG9DMBmADjFDc2w/r ljwvFw=="
+
+func TestDNSKeyTag(t *testing.T) {
+	k, err := parseDNSKey(testDNSKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyTag, err := k.KeyTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyTag != 60485 {
+		t.Fatalf("expected key tag 60485, got %d", keyTag)
+	}
+}
+
+func TestDNSKeyDS(t *testing.T) {
+	k, err := parseDNSKey(testDNSKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int]string{
+		1: "60485 5 1 2bb183af5f22588179a53b0a98631fad1a292118",
+		2: "60485 5 2 d4b7d520e7bb5f0f67674a0cceb1e3e0614b93c4f9e99b8383f6a1e4469da50a",
+	}
+	for digestType, want := range expected {
+		ds, err := k.DS("dskey.example.com.", digestType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ds.String() != want {
+			t.Errorf("expected %s, got %s", want, ds)
+		}
+		parsed, err := parseDS(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != ds {
+			t.Errorf("expected parsed %v, got %v", ds, parsed)
+		}
+	}
+
+	if _, err := k.DS("dskey.example.com.", 3); err == nil {
+		t.Error("expected an error for GOST digests")
+	}
+}
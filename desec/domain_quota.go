@@ -0,0 +1,101 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Values of the domain_quota_check provider argument.
+const (
+	domainQuotaCheckError = "error"
+	domainQuotaCheckWarn  = "warn"
+	domainQuotaCheckOff   = "off"
+)
+
+// domainQuota tracks the domains planned for creation within one run, to
+// compare them to the account's domain limit before anything is applied.
+type domainQuota struct {
+	mode string
+
+	mutex  sync.Mutex
+	loaded bool
+	// nil if the account has no limit
+	limit   *int
+	used    int
+	planned map[string]bool
+}
+
+func newDomainQuota(mode string) domainQuota {
+	return domainQuota{mode: mode, planned: make(map[string]bool)}
+}
+
+// PlanCreate records a planned domain creation, and fails if the planned
+// creations together go over the limit. In warn mode it never fails, since a
+// plan can't show warnings, and WarnCreate reports the excess instead.
+func (q *domainQuota) PlanCreate(ctx context.Context, conf *DesecConfig, domainName string) error {
+	err := q.check(ctx, conf, domainName)
+	if q.mode == domainQuotaCheckWarn {
+		return nil
+	}
+	return err
+}
+
+// WarnCreate returns a warning in warn mode, if creating the domain goes over
+// the limit together with the other creations of this run.
+func (q *domainQuota) WarnCreate(ctx context.Context, conf *DesecConfig, domainName string) diag.Diagnostics {
+	if q.mode != domainQuotaCheckWarn {
+		return nil
+	}
+	if err := q.check(ctx, conf, domainName); err != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Domain limit of the account exceeded",
+			Detail:   err.Error(),
+		}}
+	}
+	return nil
+}
+
+// check records a domain creation, and compares all creations of this run to
+// the limit. The limit and the number of domains in use are fetched once per
+// run, before the first creation.
+func (q *domainQuota) check(ctx context.Context, conf *DesecConfig, domainName string) error {
+	if q.mode == domainQuotaCheckOff {
+		return nil
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if !q.loaded {
+		account, err := getAccount(ctx, conf)
+		if err != nil {
+			return fmt.Errorf("failed to read the domain limit of the account: %w", err)
+		}
+		domains, err := getAllDomains(ctx, conf.client)
+		if err != nil {
+			return fmt.Errorf("failed to count the domains of the account: %w", err)
+		}
+		q.limit = account.LimitDomains
+		q.used = len(domains)
+		q.loaded = true
+	}
+
+	q.planned[domainName] = true
+	if q.limit == nil || q.used+len(q.planned) <= *q.limit {
+		return nil
+	}
+
+	names := make([]string, 0, len(q.planned))
+	for name := range q.planned {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("creating %d domain(s) (%s) would exceed the account's limit of %d domains, %d of which are in use",
+		len(names), strings.Join(names, ", "), *q.limit, q.used)
+}
@@ -0,0 +1,81 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func newTestQuotaConfig(t *testing.T, limit int, used int) *DesecConfig {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/auth/account/":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":            "9ab16e5c-805d-4ab1-9030-af3f5a541d47",
+				"email":         "youremailaddress@example.com",
+				"limit_domains": limit,
+			})
+		case "/domains/":
+			domains := make([]dsc.Domain, used)
+			json.NewEncoder(w).Encode(domains)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return &DesecConfig{client: c, httpClient: http.DefaultClient, token: "token"}
+}
+
+func TestDomainQuotaCountsPlannedCreates(t *testing.T) {
+	conf := newTestQuotaConfig(t, 3, 1)
+	quota := newDomainQuota(domainQuotaCheckError)
+	ctx := context.Background()
+
+	for _, name := range []string{"a.example", "b.example", "a.example"} {
+		if err := quota.PlanCreate(ctx, conf, name); err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+	}
+	if err := quota.PlanCreate(ctx, conf, "c.example"); err == nil {
+		t.Fatal("expected the third new domain to exceed the limit")
+	}
+}
+
+func TestDomainQuotaOff(t *testing.T) {
+	conf := newTestQuotaConfig(t, 1, 1)
+	quota := newDomainQuota(domainQuotaCheckOff)
+
+	if err := quota.PlanCreate(context.Background(), conf, "a.example"); err != nil {
+		t.Fatalf("expected no check, got %s", err)
+	}
+}
+
+func TestDomainQuotaWarn(t *testing.T) {
+	conf := newTestQuotaConfig(t, 2, 1)
+	quota := newDomainQuota(domainQuotaCheckWarn)
+	ctx := context.Background()
+
+	for _, name := range []string{"a.example", "b.example"} {
+		if err := quota.PlanCreate(ctx, conf, name); err != nil {
+			t.Fatalf("%s: expected no error in warn mode, got %s", name, err)
+		}
+	}
+
+	if diags := quota.WarnCreate(ctx, conf, "a.example"); len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Errorf("expected a warning when creating over the limit, got %v", diags)
+	}
+
+	quota = newDomainQuota(domainQuotaCheckWarn)
+	if diags := quota.WarnCreate(ctx, conf, "a.example"); len(diags) != 0 {
+		t.Errorf("expected no warning within the limit, got %v", diags)
+	}
+	quota = newDomainQuota(domainQuotaCheckError)
+	quota.PlanCreate(ctx, conf, "a.example")
+	if diags := quota.WarnCreate(ctx, conf, "b.example"); len(diags) != 0 {
+		t.Errorf("expected no warning in error mode, got %v", diags)
+	}
+}
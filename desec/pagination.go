@@ -0,0 +1,64 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// getAllRRSets retrieves the RRsets of a domain matching the filter. The API
+// hands out large zones in pages, which are followed until the listing is
+// complete. A failure on any page fails the whole listing, so callers never
+// mistake a partial zone for the full one.
+func getAllRRSets(ctx context.Context, c *dsc.Client, domainName string, filter *dsc.RRSetFilter) ([]dsc.RRSet, error) {
+	result := []dsc.RRSet{}
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		page, cursors, err := c.Records.GetAllPaginated(ctx, domainName, filter, cursor)
+		if err != nil {
+			if cursor == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to retrieve RRsets of %q after %d results: %w", domainName, len(result), err)
+		}
+		result = append(result, page...)
+
+		if cursors == nil || cursors.Next == "" {
+			return result, nil
+		}
+		if seen[cursors.Next] {
+			return nil, fmt.Errorf("failed to retrieve RRsets of %q: pagination cursor %q repeats", domainName, cursors.Next)
+		}
+		seen[cursors.Next] = true
+		cursor = cursors.Next
+	}
+}
+
+// getAllDomains retrieves all domains visible to the token, following
+// pagination the same way as getAllRRSets.
+func getAllDomains(ctx context.Context, c *dsc.Client) ([]dsc.Domain, error) {
+	result := []dsc.Domain{}
+	seen := make(map[string]bool)
+	cursor := ""
+	for {
+		page, cursors, err := c.Domains.GetAllPaginated(ctx, cursor)
+		if err != nil {
+			if cursor == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to retrieve domains after %d results: %w", len(result), err)
+		}
+		result = append(result, page...)
+
+		if cursors == nil || cursors.Next == "" {
+			return result, nil
+		}
+		if seen[cursors.Next] {
+			return nil, fmt.Errorf("failed to retrieve domains: pagination cursor %q repeats", cursors.Next)
+		}
+		seen[cursors.Next] = true
+		cursor = cursors.Next
+	}
+}
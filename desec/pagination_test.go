@@ -0,0 +1,111 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// pagedRRSets serves the RRsets of example.com in pages of the given size,
+// linking each page to the next the way the API does.
+func pagedRRSets(total, pageSize int, failAt string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/domains/example.com/rrsets/" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail": "Not found."}`))
+			return
+		}
+
+		cursor := req.URL.Query().Get("cursor")
+		if failAt != "" && cursor == failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		start := 0
+		if cursor != "" {
+			fmt.Sscanf(cursor, "page-%d", &start)
+		}
+		end := start + pageSize
+		if end > total {
+			end = total
+		}
+
+		if end < total {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?cursor=page-%d>; rel="next"`, req.Host, req.URL.Path, end))
+		}
+
+		created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		page := []dsc.RRSet{}
+		for i := start; i < end; i++ {
+			page = append(page, dsc.RRSet{
+				Domain:  "example.com",
+				SubName: fmt.Sprintf("host%d", i),
+				Type:    "A",
+				Records: []string{"127.0.0.1"},
+				TTL:     3600,
+				Created: &created,
+			})
+		}
+		json.NewEncoder(w).Encode(page)
+	})
+}
+
+func TestGetAllRRSetsFollowsPages(t *testing.T) {
+	c := newTestClient(t, pagedRRSets(1234, 500, ""))
+
+	rrsets, err := getAllRRSets(context.Background(), c, "example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rrsets) != 1234 {
+		t.Fatalf("expected 1234 RRsets, got %d", len(rrsets))
+	}
+}
+
+func TestGetAllRRSetsFailsOnIncompleteZone(t *testing.T) {
+	c := newTestClient(t, pagedRRSets(1234, 500, "page-1000"))
+
+	rrsets, err := getAllRRSets(context.Background(), c, "example.com", nil)
+	if err == nil {
+		t.Fatalf("expected an error, got %d RRsets", len(rrsets))
+	}
+}
+
+func TestGetAllRRSetsDetectsCursorLoop(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?cursor=again>; rel="next"`, req.Host, req.URL.Path))
+		json.NewEncoder(w).Encode([]dsc.RRSet{})
+	}))
+
+	_, err := getAllRRSets(context.Background(), c, "example.com", nil)
+	if err == nil {
+		t.Fatal("expected an error for a repeating cursor")
+	}
+}
+
+func TestDesecCacheReadsAllPages(t *testing.T) {
+	c := newTestClient(t, pagedRRSets(1001, 500, ""))
+
+	cache := NewDesecCache()
+	r, err := cache.GetRRSetById(context.Background(), c, "example.com/host1000/A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil {
+		t.Fatal("RRset from the last page is missing")
+	}
+
+	rrsets, err := cache.GetRRSetsByDomain(context.Background(), c, "missing.example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rrsets != nil {
+		t.Fatalf("expected no RRsets for a missing domain, got %v", rrsets)
+	}
+}
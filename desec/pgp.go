@@ -0,0 +1,53 @@
+package desec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// encryptWithPGPKey encrypts a secret for a PGP public key, given either in
+// ASCII armor or base64 encoded, the format used by other providers for the
+// same purpose. It returns the base64 encoded message and the fingerprint of
+// the key.
+func encryptWithPGPKey(pgpKey, secret string) (string, string, error) {
+	var entities openpgp.EntityList
+	var err error
+	if strings.Contains(pgpKey, "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+		entities, err = openpgp.ReadArmoredKeyRing(strings.NewReader(pgpKey))
+	} else {
+		var raw []byte
+		raw, err = base64.StdEncoding.DecodeString(strings.Join(strings.Fields(pgpKey), ""))
+		if err != nil {
+			return "", "", fmt.Errorf("PGP key is neither ASCII armored nor base64 encoded: %w", err)
+		}
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(raw))
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read PGP key: %w", err)
+	}
+	if len(entities) != 1 {
+		return "", "", fmt.Errorf("expected exactly one PGP key, got %d", len(entities))
+	}
+
+	buf := new(bytes.Buffer)
+	w, err := openpgp.Encrypt(buf, entities, nil, nil, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt for PGP key: %w", err)
+	}
+	_, err = w.Write([]byte(secret))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt for PGP key: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt for PGP key: %w", err)
+	}
+
+	fingerprint := hex.EncodeToString(entities[0].PrimaryKey.Fingerprint)
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), fingerprint, nil
+}
@@ -0,0 +1,66 @@
+package desec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+func TestEncryptWithPGPKey(t *testing.T) {
+	entity, err := openpgp.NewEntity("ci", "", "ci@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := new(bytes.Buffer)
+	if err := entity.Serialize(raw); err != nil {
+		t.Fatal(err)
+	}
+	armored := new(bytes.Buffer)
+	w, err := armor.Encode(armored, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(raw.Bytes())
+	w.Close()
+
+	keys := map[string]string{
+		"base64":  base64.StdEncoding.EncodeToString(raw.Bytes()),
+		"armored": armored.String(),
+	}
+	for format, key := range keys {
+		encrypted, fingerprint, err := encryptWithPGPKey(key, "4pnk7u-NHvrEkFzrhFDRTjGFyX_S")
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if fingerprint != hex.EncodeToString(entity.PrimaryKey.Fingerprint) {
+			t.Errorf("%s: unexpected fingerprint %s", format, fingerprint)
+		}
+
+		message, err := base64.StdEncoding.DecodeString(encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md, err := openpgp.ReadMessage(bytes.NewReader(message), openpgp.EntityList{entity}, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		plaintext, err := io.ReadAll(md.UnverifiedBody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plaintext) != "4pnk7u-NHvrEkFzrhFDRTjGFyX_S" {
+			t.Errorf("%s: decrypted %q", format, plaintext)
+		}
+	}
+
+	if _, _, err := encryptWithPGPKey(strings.Repeat("x", 10), "secret"); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"log"
 	"net/http"
 	"regexp"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-retryablehttp"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -16,8 +18,20 @@ import (
 )
 
 type DesecConfig struct {
-	cache  *DesecCache
-	client *dsc.Client
+	cache   *DesecCache
+	client  *dsc.Client
+	batcher *RRSetBatcher
+	// serializes read-modify-write cycles on RRsets, see setRecordValue
+	recordLocks *keyedMutex
+	quota       *domainQuota
+	rotations   *tokenRotations
+
+	// for API endpoints not covered by the client, see apiRequest
+	httpClient *http.Client
+	token      string
+
+	// the endpoint of the dynDNS protocol, see dyndnsUpdate
+	dyndnsURI string
 }
 
 // Provider -
@@ -36,17 +50,56 @@ func Provider() *schema.Provider {
 				Description:  "The API token for operations.",
 				ValidateFunc: validation.StringMatch(regexp.MustCompile("[0-9a-zA-Z_-]{28}"), "API key looks invalid"),
 			},
+			"domain_quota_check": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      domainQuotaCheckError,
+				Description:  "Whether planning more new domains than the account's limit allows is an error, a warning when the domains are created, or not checked.",
+				ValidateFunc: validation.StringInSlice([]string{domainQuotaCheckError, domainQuotaCheckWarn, domainQuotaCheckOff}, false),
+			},
+			"dyndns_uri": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("DESEC_DYNDNS_URI", defaultDynDNSURI),
+				Description: "The endpoint for dynDNS updates, used by desec_dyndns.",
+			},
 			"retry_max": {
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Description: "The max number of retries when sending an API request.",
 			},
+			"rrset_batch_window": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				Description:  "Milliseconds to wait for further RRset changes in the same domain, to send them in one bulk request. 0 disables batching.",
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"desec_account":         dataSourceAccount(),
+			"desec_domain":          dataSourceDomain(),
+			"desec_domain_for_name": dataSourceDomainForName(),
+			"desec_dnssec":          dataSourceDNSSEC(),
+			"desec_domains":         dataSourceDomains(),
+			"desec_rrset":           dataSourceRRSet(),
+			"desec_rrsets":          dataSourceRRSets(),
+			"desec_zone_export":     dataSourceZoneExport(),
+			"desec_zonefile":        dataSourceZonefile(),
+			"desec_zonefile_rrsets": dataSourceZonefileRRSets(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"desec_rrset":        resourceRRSet(),
-			"desec_domain":       resourceDomain(),
-			"desec_token":        resourceToken(),
-			"desec_token_policy": resourceTokenPolicy(),
+			"desec_account_settings":     resourceAccountSettings(),
+			"desec_acme_challenge":       resourceACMEChallenge(),
+			"desec_rrset":                resourceRRSet(),
+			"desec_record":               resourceRecord(),
+			"desec_domain":               resourceDomain(),
+			"desec_dyndns":               resourceDynDNS(),
+			"desec_delegation":           resourceDelegation(),
+			"desec_subdomain_delegation": resourceSubdomainDelegation(),
+			"desec_token":                resourceToken(),
+			"desec_token_policy":         resourceTokenPolicy(),
+			"desec_zone":                 resourceZone(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -74,11 +127,33 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		c.BaseURL = api_uri
 	}
 
+	// the same retry behavior as the client, see dsc.New
+	retryClient := retryablehttp.NewClient()
+	retryClient.RetryMax = o.RetryMax
+	retryClient.HTTPClient = o.HTTPClient
+	retryClient.Logger = o.Logger
+
 	cache := NewDesecCache()
-	return &DesecConfig{&cache, c}, nil
+	batcher := NewRRSetBatcher(c, time.Duration(d.Get("rrset_batch_window").(int))*time.Millisecond)
+	quota := newDomainQuota(d.Get("domain_quota_check").(string))
+	rotations := newTokenRotations()
+	return &DesecConfig{
+		cache:       &cache,
+		client:      c,
+		batcher:     &batcher,
+		recordLocks: &keyedMutex{},
+		quota:       &quota,
+		rotations:   &rotations,
+		httpClient:  retryClient.StandardClient(),
+		token:       token,
+		dyndnsURI:   d.Get("dyndns_uri").(string),
+	}, nil
 }
 
 func isNotFoundError(err error) bool {
+	if rawError, ok := err.(*rawAPIError); ok {
+		return rawError != nil && rawError.StatusCode == http.StatusNotFound
+	}
 	apiError, ok := err.(*dsc.APIError)
 	if !ok {
 		return false
@@ -1,10 +1,14 @@
 package desec
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
 )
 
 var testAccProviders map[string]*schema.Provider
@@ -32,3 +36,15 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal("DESEC_API_TOKEN must be set for acceptance tests")
 	}
 }
+
+// newTestClient returns a client talking to a local fake of the API.
+func newTestClient(t *testing.T, handler http.Handler) *dsc.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	o := dsc.NewDefaultClientOptions()
+	o.RetryMax = 0
+	c := dsc.New("token", o)
+	c.BaseURL = server.URL
+	return c
+}
@@ -0,0 +1,68 @@
+package desec
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+/* Implementation notes:
+ *  - The ID is the account ID. There is only one account per token, so any ID can be imported.
+ *  - The account can't be deleted through the API, and this resource doesn't try. Delete only
+ *    drops the settings from the state and leaves them as they are.
+ */
+func resourceAccountSettings() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAccountSettingsApply,
+		ReadContext:   resourceAccountSettingsRead,
+		UpdateContext: resourceAccountSettingsApply,
+		DeleteContext: resourceAccountSettingsDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"outreach_preference": {
+				Type:     schema.TypeBool,
+				Required: true,
+			},
+			"email": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceAccountSettingsApply(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	_, err := conf.apiRequest(ctx, http.MethodPatch, []string{"auth", "account"}, map[string]interface{}{
+		"outreach_preference": d.Get("outreach_preference").(bool),
+	})
+	if err != nil {
+		return apiErrorDiagnostics(err)
+	}
+
+	return resourceAccountSettingsRead(ctx, d, m)
+}
+
+func resourceAccountSettingsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	account, err := getAccount(ctx, conf)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(account.ID)
+	d.Set("outreach_preference", account.OutreachPreference)
+	d.Set("email", account.Email)
+	return nil
+}
+
+func resourceAccountSettingsDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,53 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestAccountSettingsApply(t *testing.T) {
+	account := map[string]interface{}{
+		"id":                  "9ab16e5c-805d-4ab1-9030-af3f5a541d47",
+		"email":               "youremailaddress@example.com",
+		"outreach_preference": true,
+	}
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/auth/account/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodPatch {
+			body, _ := io.ReadAll(req.Body)
+			var changes map[string]interface{}
+			json.Unmarshal(body, &changes)
+			for k, v := range changes {
+				if k != "outreach_preference" {
+					t.Errorf("unexpected field %s", k)
+				}
+				account[k] = v
+			}
+		}
+		json.NewEncoder(w).Encode(account)
+	}))
+	conf := &DesecConfig{client: c, httpClient: http.DefaultClient, token: "token"}
+
+	d := schema.TestResourceDataRaw(t, resourceAccountSettings().Schema, map[string]interface{}{
+		"outreach_preference": false,
+	})
+	diags := resourceAccountSettingsApply(context.Background(), d, conf)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if d.Id() != account["id"] || d.Get("email") != account["email"] {
+		t.Fatalf("unexpected id %q or email %q", d.Id(), d.Get("email"))
+	}
+	if account["outreach_preference"] != false || d.Get("outreach_preference") != false {
+		t.Fatalf("expected outreach preference to be disabled, got %v", account["outreach_preference"])
+	}
+}
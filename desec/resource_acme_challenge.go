@@ -0,0 +1,182 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const acmeChallengeLabel = "_acme-challenge"
+
+/* Implementation notes:
+ *  - The ID is the one of the desec_record holding the token, "domainName/_acme-challenge.subName/TXT/token"
+ *  - Tokens share the TXT RRset with those of other challenges for the same name, see setRecordValue.
+ *    ttl only applies when the challenge creates the RRset, and isn't read back from it.
+ *  - Create waits until the domain was published at or after the RRset was touched, so the
+ *    token can be validated right away. If the token was there already, it doesn't wait.
+ */
+func resourceACMEChallenge() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceACMEChallengeCreate,
+		ReadContext:   resourceACMEChallengeRead,
+		DeleteContext: resourceACMEChallengeDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"subname": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(0, 162),
+			},
+			"token": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(60, 604800),
+			},
+			"fqdn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"published": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceACMEChallengeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName := d.Get("domain").(string)
+	subName := acmeChallengeSubName(d.Get("subname").(string))
+	token := d.Get("token").(string)
+
+	domain, err := c.Domains.Get(ctx, domainName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("domain %q not found", domainName)
+		}
+		return diag.FromErr(err)
+	}
+	ttl := d.Get("ttl").(int)
+	if ttl == 0 {
+		// short lived records should expire as soon as allowed
+		ttl = domain.MinimumTTL
+	}
+
+	rrset, changed, err := setRecordValue(ctx, conf, domainName, subName, "TXT", token, ttl, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(recordId(domainName, subName, "TXT", token))
+	d.Set("ttl", ttl)
+
+	if !changed {
+		// the value was there already, nothing new gets published
+		if domain.Published != nil {
+			d.Set("published", domain.Published.Format(time.RFC3339))
+		}
+		return resourceACMEChallengeRead(ctx, d, m)
+	}
+
+	// the value is live once the domain was published at or after the write.
+	// an earlier publication, even one that happens during the wait, doesn't count.
+	var touched time.Time
+	if rrset != nil && rrset.Touched != nil {
+		touched = *rrset.Touched
+	}
+
+	err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *retry.RetryError {
+		domain, err := c.Domains.Get(ctx, domainName)
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		if domain.Published == nil || domain.Published.Before(touched) {
+			return retry.RetryableError(fmt.Errorf("domain %q not yet published", domainName))
+		}
+		d.Set("published", domain.Published.Format(time.RFC3339))
+		return nil
+	})
+	if err != nil {
+		return diag.Errorf("challenge was written, but waiting for it to be published failed: %s", err)
+	}
+
+	return resourceACMEChallengeRead(ctx, d, m)
+}
+
+func resourceACMEChallengeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var diags diag.Diagnostics
+
+	domainName, subName, recordType, token, err := recordNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	r, err := conf.cache.GetRRSetById(ctx, c, idFromNames(domainName, subName, recordType))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if r == nil || !hasRecordValue(r, recordType, token) {
+		d.SetId("")
+		return diags
+	}
+
+	validated := strings.TrimPrefix(strings.TrimPrefix(subName, acmeChallengeLabel), ".")
+	d.Set("domain", domainName)
+	d.Set("subname", validated)
+	d.Set("token", token)
+	d.Set("fqdn", r.Name)
+	return diags
+}
+
+func resourceACMEChallengeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	domainName, subName, recordType, token, err := recordNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, _, err = setRecordValue(ctx, conf, domainName, subName, recordType, token, 0, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// acmeChallengeSubName returns the subname of the challenge RRset for the
+// validation of a subname.
+func acmeChallengeSubName(subName string) string {
+	if subName == "" {
+		return acmeChallengeLabel
+	}
+	return acmeChallengeLabel + "." + subName
+}
@@ -0,0 +1,210 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestAccDesecACMEChallengeShared(t *testing.T) {
+	uuid, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	domainName := fmt.Sprintf("%s.example", uuid)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDesecDomainDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDesecACMEChallengeConfig(domainName, `"token-one", "token-two"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("desec_acme_challenge.challenge.0", "fqdn", "_acme-challenge."+domainName+"."),
+					resource.TestCheckResourceAttrSet("desec_acme_challenge.challenge.0", "published"),
+					testAccCheckDesecACMEChallengeTokens(domainName, 2),
+				),
+			},
+			{
+				// removing one challenge leaves the other token in place
+				Config: testAccCheckDesecACMEChallengeConfig(domainName, `"token-one"`),
+				Check:  testAccCheckDesecACMEChallengeTokens(domainName, 1),
+			},
+		},
+	})
+}
+
+func testAccCheckDesecACMEChallengeConfig(domainName, tokens string) string {
+	return fmt.Sprintf(`
+	resource "desec_domain" "desec-example" {
+		name = "%s"
+	}
+	resource "desec_acme_challenge" "challenge" {
+		count = length([%s])
+		domain = desec_domain.desec-example.name
+		token = [%s][count.index]
+	}
+	`, domainName, tokens, tokens)
+}
+
+func testAccCheckDesecACMEChallengeTokens(domainName string, count int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		c := testAccProvider.Meta().(*DesecConfig).client
+
+		r, err := c.Records.Get(context.TODO(), domainName, acmeChallengeLabel, "TXT")
+		if err != nil {
+			return err
+		}
+		if len(r.Records) != count {
+			return fmt.Errorf("expected %d tokens, got %v", count, r.Records)
+		}
+		return nil
+	}
+}
+
+// publishingDomain serves example.com with a publication time chosen by
+// published, and its challenge RRset from rrsets.
+type publishingDomain struct {
+	mutex     sync.Mutex
+	rrsets    *singleRRSet
+	polls     int
+	published func(polls int, touched *time.Time) time.Time
+}
+
+func (p *publishingDomain) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet && req.URL.Path == "/domains/example.com/rrsets/" {
+		p.rrsets.mutex.Lock()
+		defer p.rrsets.mutex.Unlock()
+		list := []dsc.RRSet{}
+		if p.rrsets.rrset != nil {
+			list = append(list, *p.rrsets.rrset)
+		}
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+	if req.URL.Path != "/domains/example.com/" {
+		p.rrsets.ServeHTTP(w, req)
+		return
+	}
+
+	p.rrsets.mutex.Lock()
+	var touched *time.Time
+	if p.rrsets.rrset != nil {
+		touched = p.rrsets.rrset.Touched
+	}
+	p.rrsets.mutex.Unlock()
+
+	p.mutex.Lock()
+	published := p.published(p.polls, touched)
+	p.polls++
+	p.mutex.Unlock()
+
+	json.NewEncoder(w).Encode(dsc.Domain{Name: "example.com", MinimumTTL: 60, Published: &published})
+}
+
+func testACMEChallengeCreate(t *testing.T, server *publishingDomain) *schema.ResourceData {
+	conf := newTestRecordConfig(t, server)
+	d := schema.TestResourceDataRaw(t, resourceACMEChallenge().Schema, map[string]interface{}{
+		"domain": "example.com",
+		"token":  "token",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if diags := resourceACMEChallengeCreate(ctx, d, conf); diags.HasError() {
+		t.Fatal(diags)
+	}
+	return d
+}
+
+func TestACMEChallengeWaitsForPublicationOfWrite(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour)
+	server := &publishingDomain{
+		rrsets: &singleRRSet{},
+		published: func(polls int, touched *time.Time) time.Time {
+			switch {
+			case touched == nil:
+				return start
+			case polls < 3:
+				// an unrelated publication after the first read, but before the write
+				return touched.Add(-time.Second)
+			default:
+				return touched.Add(time.Second)
+			}
+		},
+	}
+
+	d := testACMEChallengeCreate(t, server)
+
+	published, _ := time.Parse(time.RFC3339, d.Get("published").(string))
+	touched := server.rrsets.rrset.Touched
+	if published.Before(touched.Truncate(time.Second)) {
+		t.Errorf("published %s is before the write at %s", published, touched)
+	}
+}
+
+func TestACMEChallengeDoesNotWaitWithoutChange(t *testing.T) {
+	old := time.Now().UTC().Add(-time.Hour)
+	server := &publishingDomain{
+		rrsets: &singleRRSet{
+			rrset: &dsc.RRSet{Domain: "example.com", SubName: "_acme-challenge", Type: "TXT", Records: []string{`"token"`}, TTL: 60, Touched: &old},
+		},
+		// no further publication ever happens
+		published: func(polls int, touched *time.Time) time.Time {
+			return old
+		},
+	}
+
+	d := testACMEChallengeCreate(t, server)
+	if d.Id() != "example.com/_acme-challenge/TXT/token" {
+		t.Errorf("got id %q", d.Id())
+	}
+}
+
+func TestACMEChallengeKeepsConfiguredTTL(t *testing.T) {
+	old := time.Now().UTC().Add(-time.Hour)
+	server := &publishingDomain{
+		rrsets: &singleRRSet{
+			rrset: &dsc.RRSet{Domain: "example.com", SubName: "_acme-challenge", Type: "TXT", Records: []string{`"other"`}, TTL: 60, Touched: &old},
+		},
+		published: func(polls int, touched *time.Time) time.Time {
+			if touched == nil {
+				return old
+			}
+			return touched.Add(time.Second)
+		},
+	}
+	conf := newTestRecordConfig(t, server)
+	d := schema.TestResourceDataRaw(t, resourceACMEChallenge().Schema, map[string]interface{}{
+		"domain": "example.com",
+		"token":  "token",
+		"ttl":    300,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if diags := resourceACMEChallengeCreate(ctx, d, conf); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	// the RRset of the other challenge keeps its TTL, and this one keeps its configuration
+	if server.rrsets.rrset.TTL != 60 {
+		t.Errorf("shared RRset got TTL %d", server.rrsets.rrset.TTL)
+	}
+	if d.Get("ttl").(int) != 300 {
+		t.Errorf("got ttl %d, want the configured 300", d.Get("ttl"))
+	}
+}
@@ -0,0 +1,286 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+/* Implementation notes:
+ *  - The ID is "parent/child"
+ *  - The resource owns the NS and DS RRsets at the child's subname in the parent zone
+ *  - The DS set is derived from the child's keys. CustomizeDiff compares it to the live keys,
+ *    so a key rotation by deSEC shows up as an update on the next plan.
+ */
+func resourceDelegation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDelegationCreate,
+		ReadContext:   resourceDelegationRead,
+		UpdateContext: resourceDelegationUpdate,
+		DeleteContext: resourceDelegationDelete,
+		CustomizeDiff: resourceDelegationCustomizeDiff,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"parent": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"child": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"nameservers": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntBetween(60, 604800),
+			},
+			"subname": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ds": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceDelegationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	parent := d.Get("parent").(string)
+	child := d.Get("child").(string)
+	d.SetId(fmt.Sprintf("%s/%s", parent, child))
+
+	diags := resourceDelegationApply(ctx, d, m, true)
+	if diags.HasError() {
+		d.SetId("")
+	}
+	return diags
+}
+
+func resourceDelegationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var diags diag.Diagnostics
+
+	parent, child, err := delegationNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	subName, err := delegationSubName(parent, child)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ns, err := conf.cache.GetRRSetById(ctx, c, idFromNames(parent, subName, "NS"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if ns == nil {
+		d.SetId("")
+		return diags
+	}
+	ds, err := conf.cache.GetRRSetById(ctx, c, idFromNames(parent, subName, "DS"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.Set("parent", parent)
+	d.Set("child", child)
+	d.Set("subname", subName)
+	d.Set("ttl", ns.TTL)
+	d.Set("nameservers", ns.Records)
+	if ds != nil {
+		d.Set("ds", ds.Records)
+	} else {
+		d.Set("ds", []string{})
+	}
+	return diags
+}
+
+func resourceDelegationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceDelegationApply(ctx, d, m, false)
+}
+
+func resourceDelegationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	parent, child, err := delegationNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	subName, err := delegationSubName(parent, child)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// DS first, the reverse of the order they are written in
+	for _, recordType := range []string{"DS", "NS"} {
+		err = conf.batcher.Delete(ctx, parent, subName, recordType)
+		if err != nil && !isNotFoundError(err) {
+			conf.cache.Invalidate(parent)
+			return diag.FromErr(err)
+		}
+		conf.cache.Remove(idFromNames(parent, subName, recordType))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceDelegationCustomizeDiff plans an update of the DS set when the keys
+// of the child domain changed since the last apply.
+func resourceDelegationCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domain, err := c.Domains.Get(ctx, d.Get("child").(string))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+
+	live := domainDSRecords(domain)
+	old := normalizeRecordSetInterface(d.Get("ds").(*schema.Set).List())
+	if !reflect.DeepEqual(live, old) {
+		return d.SetNew("ds", live)
+	}
+	return nil
+}
+
+// resourceDelegationApply writes the NS and DS RRsets into the parent zone.
+func resourceDelegationApply(ctx context.Context, d *schema.ResourceData, m interface{}, create bool) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	parent, child, err := delegationNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	subName, err := delegationSubName(parent, child)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	domain, err := c.Domains.Get(ctx, child)
+	if err != nil {
+		if isNotFoundError(err) {
+			return diag.Errorf("child domain %q not found", child)
+		}
+		return diag.FromErr(err)
+	}
+
+	var nameservers []string
+	for _, ns := range d.Get("nameservers").(*schema.Set).List() {
+		nameservers = append(nameservers, ns.(string))
+	}
+	if len(nameservers) == 0 {
+		// default to the nameservers the child is served from
+		apex, err := conf.cache.GetRRSetById(ctx, c, idFromNames(child, "", "NS"))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if apex == nil {
+			return diag.Errorf("child domain %q has no NS records, set nameservers explicitly", child)
+		}
+		nameservers = apex.Records
+	}
+
+	ttl := d.Get("ttl").(int)
+	// NS first, so the parent never has a DS set for a name that isn't delegated
+	rrsets := []dsc.RRSet{
+		{Domain: parent, SubName: subName, Type: "NS", Records: nameservers, TTL: ttl},
+		{Domain: parent, SubName: subName, Type: "DS", Records: domainDSRecords(domain), TTL: ttl},
+	}
+	for _, r := range rrsets {
+		var result *dsc.RRSet
+		if create {
+			if len(r.Records) == 0 {
+				continue
+			}
+			result, err = conf.batcher.Create(ctx, r)
+		} else {
+			result, err = conf.batcher.Update(ctx, r.Domain, r.SubName, r.Type, r)
+			if isNotFoundError(err) {
+				// the DS set is left out on create while the child has no keys
+				if len(r.Records) == 0 {
+					result, err = nil, nil
+				} else {
+					result, err = conf.batcher.Create(ctx, r)
+				}
+			}
+		}
+		if err != nil {
+			conf.cache.Invalidate(parent)
+			return diag.FromErr(err)
+		}
+		if result != nil {
+			conf.cache.Put(*result)
+		} else {
+			conf.cache.Remove(idFromNames(r.Domain, r.SubName, r.Type))
+		}
+	}
+
+	return resourceDelegationRead(ctx, d, m)
+}
+
+// domainDSRecords collects the DS records of all keys of a domain, normalized
+// and sorted.
+func domainDSRecords(domain *dsc.Domain) []string {
+	result := []string{}
+	for _, k := range flattenDomain(domain)["keys"].([]interface{}) {
+		result = append(result, k.(map[string]interface{})["ds"].([]string)...)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func delegationSubName(parent, child string) (string, error) {
+	subName, err := subNameFromQName(child, parent)
+	if err != nil {
+		return "", err
+	}
+	if subName == "" {
+		return "", fmt.Errorf("child %q must be below parent %q", child, parent)
+	}
+	return subName, nil
+}
+
+func delegationNamesFromId(id string) (string, string, error) {
+	parent, child, ok := strings.Cut(id, "/")
+	if !ok || parent == "" || child == "" {
+		return "", "", fmt.Errorf("invalid id %q specified, should be in format \"parent/child\" for import", id)
+	}
+	return parent, child, nil
+}
@@ -0,0 +1,164 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestAccDesecDelegationBasic(t *testing.T) {
+	uuid, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	parentName := fmt.Sprintf("%s.example", uuid)
+	childName := fmt.Sprintf("dev.%s", parentName)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDesecDomainDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDesecDelegationConfigBasic(parentName, childName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("desec_delegation.dev", "id", parentName+"/"+childName),
+					resource.TestCheckResourceAttr("desec_delegation.dev", "subname", "dev"),
+					resource.TestCheckResourceAttrSet("desec_delegation.dev", "ds.#"),
+					testAccCheckDesecDelegationMatchesKeys,
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDesecDelegationConfigBasic(parentName, childName string) string {
+	return fmt.Sprintf(`
+	resource "desec_domain" "parent" {
+		name = "%s"
+	}
+	resource "desec_domain" "child" {
+		name = "%s"
+	}
+	resource "desec_delegation" "dev" {
+		parent = desec_domain.parent.name
+		child = desec_domain.child.name
+	}
+	`, parentName, childName)
+}
+
+func testAccCheckDesecDelegationMatchesKeys(s *terraform.State) error {
+	c := testAccProvider.Meta().(*DesecConfig).client
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "desec_delegation" {
+			continue
+		}
+
+		parent, child, err := delegationNamesFromId(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		domain, err := c.Domains.Get(context.TODO(), child)
+		if err != nil {
+			return err
+		}
+		ds, err := c.Records.Get(context.TODO(), parent, "dev", "DS")
+		if err != nil {
+			return err
+		}
+		if fmt.Sprint(normalizeRecordSet(ds.Records)) != fmt.Sprint(domainDSRecords(domain)) {
+			return fmt.Errorf("DS records %v don't match the keys of %s", ds.Records, child)
+		}
+	}
+
+	return nil
+}
+
+func TestDelegationUpdateCreatesMissingDS(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mutex sync.Mutex
+	rrsets := map[string]dsc.RRSet{
+		"NS": {Domain: "example.com", SubName: "dev", Type: "NS", Records: []string{"ns1.desec.io."}, TTL: 3600, Created: &created},
+	}
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		body, _ := io.ReadAll(req.Body)
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/domains/dev.example.com/":
+			// the child got a key after the delegation was created
+			json.NewEncoder(w).Encode(dsc.Domain{
+				Name:    "dev.example.com",
+				Created: &created,
+				Keys:    []dsc.DomainKey{{DS: []string{"12345 13 2 abcd"}}},
+			})
+		case req.Method == http.MethodGet && req.URL.Path == "/domains/example.com/rrsets/":
+			result := []dsc.RRSet{}
+			for _, r := range rrsets {
+				result = append(result, r)
+			}
+			json.NewEncoder(w).Encode(result)
+		case req.Method == http.MethodPatch:
+			var r dsc.RRSet
+			json.Unmarshal(body, &r)
+			existing, ok := rrsets[r.Type]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"detail": "Not found."}`))
+				return
+			}
+			existing.Records = r.Records
+			rrsets[r.Type] = existing
+			json.NewEncoder(w).Encode(existing)
+		case req.Method == http.MethodPost && req.URL.Path == "/domains/example.com/rrsets/":
+			var r dsc.RRSet
+			json.Unmarshal(body, &r)
+			r.Domain = "example.com"
+			r.Created = &created
+			rrsets[r.Type] = r
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(r)
+		default:
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	cache := NewDesecCache()
+	batcher := NewRRSetBatcher(c, 0)
+	conf := &DesecConfig{cache: &cache, client: c, batcher: &batcher}
+
+	d := schema.TestResourceDataRaw(t, resourceDelegation().Schema, map[string]interface{}{
+		"parent":      "example.com",
+		"child":       "dev.example.com",
+		"nameservers": []interface{}{"ns1.desec.io."},
+	})
+	d.SetId("example.com/dev.example.com")
+
+	if diags := resourceDelegationUpdate(context.Background(), d, conf); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if ds, ok := rrsets["DS"]; !ok || !reflect.DeepEqual(ds.Records, []string{"12345 13 2 abcd"}) {
+		t.Errorf("DS RRset not created, got %v", rrsets)
+	}
+	if ds := d.Get("ds").(*schema.Set); ds.Len() != 1 || !ds.Contains("12345 13 2 abcd") {
+		t.Errorf("got ds %v", ds.List())
+	}
+}
@@ -2,6 +2,10 @@ package desec
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -14,7 +18,9 @@ func resourceDomain() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceDomainCreate,
 		ReadContext:   resourceDomainRead,
+		UpdateContext: resourceDomainUpdate,
 		DeleteContext: resourceDomainDelete,
+		CustomizeDiff: resourceDomainCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -36,50 +42,80 @@ func resourceDomain() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
-			"keys": {
-				Type:     schema.TypeList,
-				Computed: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"dnskey": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
-						"ds": {
-							Type:     schema.TypeSet,
-							Computed: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-						},
-						"flags": {
-							Type:     schema.TypeInt,
-							Computed: true,
-						},
-						"keytype": {
-							Type:     schema.TypeString,
-							Computed: true,
-						},
+			// only used to seed the domain on creation, never read back. later
+			// changes are kept in the state without touching the domain.
+			"zonefile": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"keys": domainKeysSchema(),
+		},
+	}
+}
+
+func domainKeysSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"dnskey": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"ds": {
+					Type:     schema.TypeSet,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
 					},
 				},
+				"flags": {
+					Type:     schema.TypeInt,
+					Computed: true,
+				},
+				"keytype": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
 			},
 		},
 	}
 }
 
+// resourceDomainCustomizeDiff checks planned creations against the domain
+// limit of the account, see domainQuota.
+func resourceDomainCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" || !d.NewValueKnown("name") {
+		return nil
+	}
+
+	conf := m.(*DesecConfig)
+	return conf.quota.PlanCreate(ctx, conf, d.Get("name").(string))
+}
+
 func resourceDomainCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	conf := m.(*DesecConfig)
-	conf.cache.Clear()
 	c := conf.client
 
 	domainName := d.Get("name").(string)
-	domain, err := c.Domains.Create(ctx, domainName)
+	conf.cache.Invalidate(domainName)
+
+	diags := conf.quota.WarnCreate(ctx, conf, domainName)
+
+	var domain *dsc.Domain
+	var err error
+	if zonefile := d.Get("zonefile").(string); zonefile != "" {
+		domain, err = createDomainFromZonefile(ctx, conf, domainName, zonefile)
+	} else {
+		domain, err = c.Domains.Create(ctx, domainName)
+	}
 	if err != nil {
-		return diag.FromErr(err)
+		return append(diags, apiErrorDiagnostics(err)...)
 	}
 
 	domainIntoData(domain, d)
-	return nil
+	return diags
 }
 
 func resourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -99,12 +135,17 @@ func resourceDomainRead(ctx context.Context, d *schema.ResourceData, m interface
 	return nil
 }
 
+func resourceDomainUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// only zonefile can change in place, and it has no effect on an existing domain
+	return resourceDomainRead(ctx, d, m)
+}
+
 func resourceDomainDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	conf := m.(*DesecConfig)
-	conf.cache.Clear()
 	c := conf.client
 
 	err := c.Domains.Delete(ctx, d.Id())
+	conf.cache.Invalidate(d.Id())
 	if err != nil && !isNotFoundError(err) {
 		return diag.FromErr(err)
 	}
@@ -114,21 +155,126 @@ func resourceDomainDelete(ctx context.Context, d *schema.ResourceData, m interfa
 }
 
 func domainIntoData(domain *dsc.Domain, d *schema.ResourceData) {
-	d.Set("created", domain.Created.Format(time.RFC3339))
-	d.Set("name", domain.Name)
-	d.Set("minimum_ttl", domain.MinimumTTL)
+	for k, v := range flattenDomain(domain) {
+		d.Set(k, v)
+	}
+	d.SetId(domain.Name)
+}
+
+func flattenDomain(domain *dsc.Domain) map[string]interface{} {
+	result := make(map[string]interface{})
+	result["created"] = domain.Created.Format(time.RFC3339)
+	result["name"] = domain.Name
+	result["minimum_ttl"] = domain.MinimumTTL
 	if domain.Published != nil {
-		d.Set("published", domain.Published.Format(time.RFC3339))
+		result["published"] = domain.Published.Format(time.RFC3339)
 	}
-	keys := make([]*map[string]interface{}, len(domain.Keys))
+	keys := make([]interface{}, len(domain.Keys))
 	for i, k := range domain.Keys {
 		key := make(map[string]interface{})
 		key["dnskey"] = k.DNSKey
 		key["ds"] = k.DS
 		key["flags"] = k.Flags
 		key["keytype"] = k.KeyType
-		keys[i] = &key
+		keys[i] = key
 	}
-	d.Set("keys", keys)
-	d.SetId(domain.Name)
+	result["keys"] = keys
+	return result
+}
+
+// createDomainFromZonefile creates a domain with records imported from zone
+// file text, which the client doesn't support.
+func createDomainFromZonefile(ctx context.Context, conf *DesecConfig, domainName, zonefile string) (*dsc.Domain, error) {
+	body, err := conf.apiRequest(ctx, http.MethodPost, []string{"domains"}, map[string]string{
+		"name":     domainName,
+		"zonefile": zonefile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var domain dsc.Domain
+	err = json.Unmarshal(body, &domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return &domain, nil
+}
+
+// getDomainZonefile exports a domain's contents in zone file format.
+func getDomainZonefile(ctx context.Context, conf *DesecConfig, domainName string) (string, error) {
+	zonefile, err := conf.apiRequest(ctx, http.MethodGet, []string{"domains", domainName, "zonefile"}, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(zonefile), nil
+}
+
+// filterZonefile drops records from zone file text, if drop returns true for
+// their owner name and type. Directives, comments and blank lines are kept.
+func filterZonefile(zonefile string, drop func(owner, recordType string) bool) string {
+	var result strings.Builder
+	var owner string
+
+	lines := strings.SplitAfter(zonefile, "\n")
+	for i := 0; i < len(lines); i++ {
+		entry := lines[i]
+		// records in parentheses span multiple lines
+		for strings.Count(stripZonefileComment(entry), "(") > strings.Count(stripZonefileComment(entry), ")") && i+1 < len(lines) {
+			i++
+			entry += lines[i]
+		}
+
+		fields := strings.Fields(stripZonefileComment(entry))
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "$") {
+			result.WriteString(entry)
+			continue
+		}
+		// a record without owner continues the previous one
+		if entry[0] != ' ' && entry[0] != '\t' {
+			owner = fields[0]
+			fields = fields[1:]
+		}
+
+		if drop(owner, zonefileRecordType(fields)) {
+			continue
+		}
+		result.WriteString(entry)
+	}
+
+	return result.String()
+}
+
+// zonefileRecordType finds the type in the fields of a record after its owner,
+// skipping the optional TTL and class.
+func zonefileRecordType(fields []string) string {
+	for _, f := range fields {
+		f = strings.ToUpper(f)
+		switch {
+		case f == "IN" || f == "CH" || f == "HS" || f == "CS":
+			continue
+		case f[0] >= '0' && f[0] <= '9':
+			continue
+		default:
+			return f
+		}
+	}
+	return ""
+}
+
+func stripZonefileComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
 }
@@ -74,3 +74,24 @@ func testAccCheckDesecDomainExists(s *terraform.State) error {
 
 	return nil
 }
+
+func TestFilterZonefile(t *testing.T) {
+	zonefile := `example.com. 300 IN SOA get.desec.io. get.desec.io. 2024 86400 3600 2419200 3600
+example.com. 3600 IN NS ns1.desec.io.
+example.com. 3600 IN NS ns2.desec.org.
+example.com. 3600 IN DNSKEY ( 257 3 13
+	aGVsbG8= ) ; ksk
+example.com. 3600 IN TXT "v=spf1 -all; not a comment"
+sub.example.com. 3600 IN NS ns.elsewhere.example.
+`
+	drop := func(owner, recordType string) bool {
+		return recordType == "SOA" || recordType == "DNSKEY" || (owner == "example.com." && recordType == "NS")
+	}
+
+	expected := `example.com. 3600 IN TXT "v=spf1 -all; not a comment"
+sub.example.com. 3600 IN NS ns.elsewhere.example.
+`
+	if result := filterZonefile(zonefile, drop); result != expected {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+}
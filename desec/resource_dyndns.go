@@ -0,0 +1,208 @@
+package desec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+const defaultDynDNSURI = "https://update.dedyn.io/"
+
+/* Implementation notes:
+ *  - The ID is the hostname
+ *  - Updates go through the dynDNS protocol, with the hostname and API token as basic auth
+ *    credentials. The result is read back through the RRset API.
+ *  - An address that isn't set is removed, the dynDNS endpoint deletes records for empty values
+ */
+func resourceDynDNS() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDynDNSCreate,
+		ReadContext:   resourceDynDNSRead,
+		UpdateContext: resourceDynDNSUpdate,
+		DeleteContext: resourceDynDNSDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"hostname": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return strings.EqualFold(strings.TrimSuffix(old, "."), strings.TrimSuffix(new, "."))
+				},
+			},
+			"ipv4": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPv4Address,
+				AtLeastOneOf: []string{"ipv4", "ipv6"},
+			},
+			"ipv6": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPv6Address,
+				AtLeastOneOf: []string{"ipv4", "ipv6"},
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					// the server may shorten addresses differently
+					return old != "" && new != "" && net.ParseIP(old).Equal(net.ParseIP(new))
+				},
+			},
+			"domain": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"subname": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceDynDNSCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	hostname := strings.ToLower(strings.TrimSuffix(d.Get("hostname").(string), "."))
+	d.SetId(hostname)
+
+	diags := resourceDynDNSApply(ctx, d, m)
+	if diags.HasError() {
+		d.SetId("")
+	}
+	return diags
+}
+
+func resourceDynDNSRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var diags diag.Diagnostics
+
+	hostname := d.Id()
+	domainName := d.Get("domain").(string)
+	if domainName == "" {
+		domain, err := c.Domains.GetResponsible(ctx, hostname)
+		if err != nil {
+			var notFound *dsc.NotFoundError
+			if errors.As(err, &notFound) {
+				d.SetId("")
+				return diags
+			}
+			return diag.FromErr(err)
+		}
+		domainName = domain.Name
+	}
+	subName, err := subNameFromQName(hostname, domainName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	addresses := make(map[string]string)
+	found := false
+	for _, recordType := range []string{"A", "AAAA"} {
+		r, err := conf.cache.GetRRSetById(ctx, c, idFromNames(domainName, subName, recordType))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if r != nil && len(r.Records) > 0 {
+			addresses[recordType] = r.Records[0]
+			found = true
+		}
+	}
+	if !found {
+		d.SetId("")
+		return diags
+	}
+
+	d.Set("hostname", hostname)
+	d.Set("domain", domainName)
+	d.Set("subname", subName)
+	d.Set("ipv4", addresses["A"])
+	d.Set("ipv6", addresses["AAAA"])
+	return diags
+}
+
+func resourceDynDNSUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceDynDNSApply(ctx, d, m)
+}
+
+func resourceDynDNSDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	err := dyndnsUpdate(ctx, conf, d.Id(), "", "")
+	if err != nil && !isNotFoundError(err) {
+		return diag.FromErr(err)
+	}
+	conf.cache.Invalidate(d.Get("domain").(string))
+
+	d.SetId("")
+	return nil
+}
+
+func resourceDynDNSApply(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	hostname := d.Id()
+	err := dyndnsUpdate(ctx, conf, hostname, d.Get("ipv4").(string), d.Get("ipv6").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	domain, err := c.Domains.GetResponsible(ctx, hostname)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// the change didn't go through the RRset API, so the cache doesn't know about it
+	conf.cache.Invalidate(domain.Name)
+	d.Set("domain", domain.Name)
+
+	return resourceDynDNSRead(ctx, d, m)
+}
+
+// dyndnsUpdate sets the addresses of a hostname through the dynDNS protocol.
+// Empty addresses remove the respective records.
+func dyndnsUpdate(ctx context.Context, conf *DesecConfig, hostname, ipv4, ipv6 string) error {
+	endpoint, err := url.Parse(conf.dyndnsURI)
+	if err != nil {
+		return fmt.Errorf("failed to create endpoint: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("hostname", hostname)
+	query.Set("myipv4", ipv4)
+	query.Set("myipv6", ipv6)
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(hostname, conf.token)
+
+	resp, err := conf.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call dynDNS endpoint: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || !strings.HasPrefix(string(body), "good") {
+		return &rawAPIError{StatusCode: resp.StatusCode, Body: body}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// dyndnsServer stands in for both the dynDNS endpoint and the parts of the
+// API that read the result back, for the domain home.dedyn.io.
+type dyndnsServer struct {
+	t       *testing.T
+	mutex   sync.Mutex
+	records map[string]string
+}
+
+func (s *dyndnsServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch req.URL.Path {
+	case "/update":
+		user, password, ok := req.BasicAuth()
+		if !ok || user != "home.dedyn.io" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("badauth"))
+			return
+		}
+		query := req.URL.Query()
+		if query.Get("hostname") != "home.dedyn.io" {
+			s.t.Errorf("unexpected hostname %q", query.Get("hostname"))
+		}
+		for param, recordType := range map[string]string{"myipv4": "A", "myipv6": "AAAA"} {
+			if !query.Has(param) {
+				s.t.Errorf("missing parameter %s, the server would use the client's address", param)
+			}
+			if v := query.Get(param); v != "" {
+				s.records[recordType] = v
+			} else {
+				delete(s.records, recordType)
+			}
+		}
+		w.Write([]byte("good"))
+	case "/domains/":
+		json.NewEncoder(w).Encode([]dsc.Domain{{Name: "home.dedyn.io"}})
+	case "/domains/home.dedyn.io/rrsets/":
+		rrsets := []dsc.RRSet{}
+		for recordType, address := range s.records {
+			rrsets = append(rrsets, dsc.RRSet{Domain: "home.dedyn.io", Type: recordType, Records: []string{address}, TTL: 60})
+		}
+		json.NewEncoder(w).Encode(rrsets)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+	}
+}
+
+func TestDynDNSUpdateAndReadBack(t *testing.T) {
+	server := &dyndnsServer{t: t, records: map[string]string{"AAAA": "2001:db8::1"}}
+	c := newTestClient(t, server)
+	cache := NewDesecCache()
+	conf := &DesecConfig{
+		cache:      &cache,
+		client:     c,
+		httpClient: http.DefaultClient,
+		token:      "token",
+		dyndnsURI:  c.BaseURL + "/update",
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceDynDNS().Schema, map[string]interface{}{
+		"hostname": "home.dedyn.io.",
+		"ipv4":     "192.0.2.1",
+	})
+	diags := resourceDynDNSCreate(context.Background(), d, conf)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	if d.Id() != "home.dedyn.io" || d.Get("domain") != "home.dedyn.io" || d.Get("subname") != "" {
+		t.Fatalf("unexpected id %q, domain %q, subname %q", d.Id(), d.Get("domain"), d.Get("subname"))
+	}
+	if d.Get("ipv4") != "192.0.2.1" || d.Get("ipv6") != "" {
+		t.Fatalf("expected only the IPv4 address to be left, got %q and %q", d.Get("ipv4"), d.Get("ipv6"))
+	}
+
+	diags = resourceDynDNSDelete(context.Background(), d, conf)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if len(server.records) != 0 {
+		t.Fatalf("expected all records to be removed, got %v", server.records)
+	}
+}
+
+func TestDynDNSUpdateRejected(t *testing.T) {
+	server := &dyndnsServer{t: t, records: map[string]string{}}
+	c := newTestClient(t, server)
+	conf := &DesecConfig{
+		client:     c,
+		httpClient: http.DefaultClient,
+		token:      "wrong",
+		dyndnsURI:  c.BaseURL + "/update",
+	}
+
+	err := dyndnsUpdate(context.Background(), conf, "home.dedyn.io", "192.0.2.1", "")
+	if err == nil {
+		t.Fatal("expected an error for bad credentials")
+	}
+}
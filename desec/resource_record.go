@@ -0,0 +1,318 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// How often a record change is attempted when concurrent changes get in the way.
+const recordMaxAttempts = 5
+
+/* Implementation notes:
+ *  - The ID is "domainName/subName/type/value"
+ *  - The resource owns one value in an RRset that may hold values of other resources. Changes
+ *    are read-modify-write cycles, serialized per RRset within the provider, and checked by
+ *    reading the RRset again, since the API has no way to make them conditional.
+ *  - The TTL is shared by all values of the RRset, so ttl only applies when the RRset is created.
+ */
+func resourceRecord() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRecordCreate,
+		ReadContext:   resourceRecordRead,
+		UpdateContext: resourceRecordUpdate,
+		DeleteContext: resourceRecordDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"subname": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(0, 178),
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"value": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return normalizeLongRecord(old) == normalizeLongRecord(new)
+				},
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(60, 604800),
+			},
+		},
+	}
+}
+
+func resourceRecordCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	domainName := d.Get("domain").(string)
+	subName := d.Get("subname").(string)
+	recordType := d.Get("type").(string)
+	value := d.Get("value").(string)
+	ttl := d.Get("ttl").(int)
+	if ttl == 0 {
+		ttl = 3600
+	}
+
+	_, _, err := setRecordValue(ctx, conf, domainName, subName, recordType, value, ttl, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(recordId(domainName, subName, recordType, value))
+	return resourceRecordRead(ctx, d, m)
+}
+
+func resourceRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var diags diag.Diagnostics
+
+	domainName, subName, recordType, value, err := recordNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	r, err := conf.cache.GetRRSetById(ctx, c, idFromNames(domainName, subName, recordType))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if r == nil || !hasRecordValue(r, recordType, value) {
+		d.SetId("")
+		return diags
+	}
+
+	d.Set("domain", domainName)
+	d.Set("subname", subName)
+	d.Set("type", recordType)
+	d.Set("value", value)
+	// a configured ttl is kept, since other values may have created the RRset
+	if _, ok := d.GetOk("ttl"); !ok {
+		d.Set("ttl", r.TTL)
+	}
+	return diags
+}
+
+func resourceRecordUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// only ttl can change in place, and it has no effect on an existing RRset
+	return resourceRecordRead(ctx, d, m)
+}
+
+func resourceRecordDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+
+	domainName, subName, recordType, value, err := recordNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, _, err = setRecordValue(ctx, conf, domainName, subName, recordType, value, 0, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// setRecordValue adds a value to an RRset, or removes it, leaving all other
+// values alone. The RRset is created with the given TTL if it doesn't exist,
+// and deleted once its last value is removed. An existing RRset keeps its TTL,
+// which all of its values share. It returns the RRset as it is afterwards, or nil if it was deleted, and
+// whether it had to be written.
+func setRecordValue(ctx context.Context, conf *DesecConfig, domainName, subName, recordType, value string, ttl int, present bool) (*dsc.RRSet, bool, error) {
+	c := conf.client
+	id := idFromNames(domainName, subName, recordType)
+
+	unlock := conf.recordLocks.Lock(id)
+	defer unlock()
+
+	quoted := quoteRecordSet(recordType, []interface{}{value})[0]
+
+	var createErr, lastErr error
+	changed := false
+	for attempt := 0; attempt < recordMaxAttempts; attempt++ {
+		if lastErr != nil {
+			select {
+			case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+			case <-ctx.Done():
+				return nil, changed, ctx.Err()
+			}
+		}
+
+		current, err := c.Records.Get(ctx, domainName, subName, recordType)
+		if err != nil {
+			if !isNotFoundError(err) {
+				return nil, changed, err
+			}
+			current = nil
+		}
+
+		if current == nil && createErr != nil {
+			// the creation wasn't rejected because of a concurrent one
+			return nil, changed, createErr
+		}
+		createErr = nil
+
+		has := current != nil && hasRecordValue(current, recordType, value)
+		if has == present {
+			if current != nil {
+				conf.cache.Put(*current)
+			} else {
+				conf.cache.Remove(id)
+			}
+			return current, changed, nil
+		}
+
+		records := []string{}
+		if current != nil {
+			for _, rec := range current.Records {
+				if normalizeLongRecord(rec) != normalizeLongRecord(quoted) {
+					records = append(records, rec)
+				}
+			}
+		}
+		if present {
+			records = append(records, quoted)
+		}
+
+		conflict := false
+		switch {
+		case current == nil:
+			_, err = c.Records.Create(ctx, dsc.RRSet{
+				Domain:  domainName,
+				SubName: subName,
+				Type:    recordType,
+				Records: records,
+				TTL:     ttl,
+			})
+			if isBadRequestError(err) {
+				// most likely created concurrently, which the next read tells
+				createErr = err
+			}
+			conflict = isBadRequestError(err)
+		case len(records) == 0:
+			err = c.Records.Delete(ctx, domainName, subName, recordType)
+			conflict = isNotFoundError(err)
+		default:
+			r := dsc.RRSet{Records: records, TTL: current.TTL}
+			_, err = c.Records.Update(ctx, domainName, subName, recordType, r)
+			// deleted concurrently
+			conflict = isNotFoundError(err)
+		}
+
+		if err != nil && !conflict {
+			conf.cache.Invalidate(domainName)
+			return nil, changed, err
+		}
+		if err == nil {
+			changed = true
+		}
+		// on success, the next iteration reads the RRset again to confirm
+		lastErr = err
+	}
+
+	conf.cache.Invalidate(domainName)
+	if lastErr != nil {
+		return nil, changed, fmt.Errorf("failed to change RRset %q after %d attempts: %w", id, recordMaxAttempts, lastErr)
+	}
+	return nil, changed, fmt.Errorf("failed to change RRset %q after %d attempts, it keeps being modified concurrently", id, recordMaxAttempts)
+}
+
+func hasRecordValue(r *dsc.RRSet, recordType, value string) bool {
+	want := normalizeLongRecord(quoteRecordSet(recordType, []interface{}{value})[0])
+	for _, rec := range r.Records {
+		if normalizeLongRecord(rec) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func isBadRequestError(err error) bool {
+	apiError, ok := err.(*dsc.APIError)
+	return ok && apiError != nil && apiError.StatusCode == http.StatusBadRequest
+}
+
+func recordId(domainName, subName, recordType, value string) string {
+	return fmt.Sprintf("%s/%s", idFromNames(domainName, subName, recordType), value)
+}
+
+func recordNamesFromId(id string) (string, string, string, string, error) {
+	idAttr := strings.SplitN(id, "/", 4)
+	if len(idAttr) != 4 {
+		return "", "", "", "", fmt.Errorf("invalid id %q specified, should be in format \"domainName/subName/type/value\" for import", id)
+	}
+
+	domainName, subName, recordType, err := namesFromId(strings.Join(idAttr[:3], "/"))
+	if err != nil {
+		return "", "", "", "", err
+	}
+	return domainName, subName, recordType, idAttr[3], nil
+}
+
+// keyedMutex serializes work on the same key, such as changes to one RRset.
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyedMutexEntry
+}
+
+type keyedMutexEntry struct {
+	sync.Mutex
+	users int
+}
+
+// Lock locks the given key and returns the function to unlock it.
+func (k *keyedMutex) Lock(key string) func() {
+	k.mutex.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedMutexEntry)
+	}
+	entry := k.locks[key]
+	if entry == nil {
+		entry = &keyedMutexEntry{}
+		k.locks[key] = entry
+	}
+	entry.users++
+	k.mutex.Unlock()
+
+	entry.Lock()
+	return func() {
+		entry.Unlock()
+		k.mutex.Lock()
+		entry.users--
+		if entry.users == 0 {
+			delete(k.locks, key)
+		}
+		k.mutex.Unlock()
+	}
+}
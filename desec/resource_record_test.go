@@ -0,0 +1,231 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// singleRRSet serves the RRset API for a single TXT RRset at the apex.
+type singleRRSet struct {
+	mutex sync.Mutex
+	rrset *dsc.RRSet
+	// called before a POST is handled
+	beforeCreate func(s *singleRRSet)
+}
+
+func (s *singleRRSet) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	var in dsc.RRSet
+	json.Unmarshal(body, &in)
+	now := time.Now().UTC()
+
+	switch req.Method {
+	case http.MethodGet:
+		if s.rrset == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"detail": "Not found."}`)
+			return
+		}
+	case http.MethodPost:
+		if s.beforeCreate != nil {
+			s.beforeCreate(s)
+			s.beforeCreate = nil
+		}
+		if s.rrset != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"non_field_errors": ["Another RRset with the same subdomain and type exists for this domain."]}`)
+			return
+		}
+		in.Domain = "example.com"
+		in.Touched = &now
+		s.rrset = &in
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPatch:
+		if s.rrset == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.rrset.Records = in.Records
+		s.rrset.TTL = in.TTL
+		s.rrset.Touched = &now
+	case http.MethodDelete:
+		s.rrset = nil
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	json.NewEncoder(w).Encode(s.rrset)
+}
+
+func (s *singleRRSet) records() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.rrset == nil {
+		return nil
+	}
+	return normalizeRecordSet(s.rrset.Records)
+}
+
+func newTestRecordConfig(t *testing.T, handler http.Handler) *DesecConfig {
+	cache := NewDesecCache()
+	return &DesecConfig{cache: &cache, client: newTestClient(t, handler), recordLocks: &keyedMutex{}}
+}
+
+func TestSetRecordValueSharesRRSet(t *testing.T) {
+	server := &singleRRSet{}
+	conf := newTestRecordConfig(t, server)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for _, value := range []string{"one", "two", "three"} {
+		wg.Add(1)
+		go func(value string) {
+			defer wg.Done()
+			if _, _, err := setRecordValue(ctx, conf, "example.com", "", "TXT", value, 3600, true); err != nil {
+				t.Error(err)
+			}
+		}(value)
+	}
+	wg.Wait()
+
+	if got := fmt.Sprint(server.records()); got != "[one three two]" {
+		t.Fatalf("expected all values, got %s", got)
+	}
+
+	if _, _, err := setRecordValue(ctx, conf, "example.com", "", "TXT", "two", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(server.records()); got != "[one three]" {
+		t.Fatalf("expected one value removed, got %s", got)
+	}
+
+	for _, value := range []string{"one", "three"} {
+		r, _, err := setRecordValue(ctx, conf, "example.com", "", "TXT", value, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value == "three" && r != nil {
+			t.Fatalf("expected the RRset to be gone, got %v", r)
+		}
+	}
+	if server.records() != nil {
+		t.Fatalf("expected the RRset to be deleted, got %v", server.records())
+	}
+}
+
+func TestSetRecordValueRetriesConcurrentCreate(t *testing.T) {
+	server := &singleRRSet{
+		// someone else creates the RRset just before us
+		beforeCreate: func(s *singleRRSet) {
+			s.rrset = &dsc.RRSet{Domain: "example.com", Type: "TXT", Records: []string{`"other"`}, TTL: 3600}
+		},
+	}
+	conf := newTestRecordConfig(t, server)
+
+	_, _, err := setRecordValue(context.Background(), conf, "example.com", "", "TXT", "mine", 3600, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(server.records()); got != "[mine other]" {
+		t.Fatalf("expected both values, got %s", got)
+	}
+}
+
+func TestSetRecordValueReportsChange(t *testing.T) {
+	server := &singleRRSet{}
+	conf := newTestRecordConfig(t, server)
+	ctx := context.Background()
+
+	steps := []struct {
+		present bool
+		changed bool
+	}{
+		{present: true, changed: true},
+		{present: true, changed: false},
+		{present: false, changed: true},
+		{present: false, changed: false},
+	}
+	for i, step := range steps {
+		_, changed, err := setRecordValue(ctx, conf, "example.com", "", "TXT", "value", 3600, step.present)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != step.changed {
+			t.Errorf("step %d: got changed %t, want %t", i, changed, step.changed)
+		}
+	}
+}
+
+func TestSetRecordValueKeepsTTL(t *testing.T) {
+	server := &singleRRSet{}
+	conf := newTestRecordConfig(t, server)
+	ctx := context.Background()
+
+	if _, _, err := setRecordValue(ctx, conf, "example.com", "", "TXT", "one", 300, true); err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := setRecordValue(ctx, conf, "example.com", "", "TXT", "two", 3600, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.TTL != 300 || len(r.Records) != 2 {
+		t.Fatalf("expected the second value to keep the TTL of the RRset, got %v", r)
+	}
+
+	// adding a value that is there already changes nothing, whatever its TTL
+	if _, changed, err := setRecordValue(ctx, conf, "example.com", "", "TXT", "two", 60, true); err != nil || changed {
+		t.Fatalf("expected no change, got %t, %v", changed, err)
+	}
+}
+
+func TestRecordReadKeepsConfiguredTTL(t *testing.T) {
+	conf := newTestRecordConfig(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode([]dsc.RRSet{
+			{Domain: "example.com", Type: "TXT", Records: []string{`"one"`, `"two"`}, TTL: 300},
+		})
+	}))
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		config map[string]interface{}
+		ttl    int
+	}{
+		{config: map[string]interface{}{"ttl": 3600}, ttl: 3600},
+		{config: map[string]interface{}{}, ttl: 300},
+	} {
+		test.config["domain"] = "example.com"
+		test.config["subname"] = ""
+		test.config["type"] = "TXT"
+		test.config["value"] = "one"
+		d := schema.TestResourceDataRaw(t, resourceRecord().Schema, test.config)
+		d.SetId("example.com/@/TXT/one")
+		if diags := resourceRecordRead(ctx, d, conf); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if d.Get("ttl").(int) != test.ttl {
+			t.Errorf("%v: got ttl %d, want %d", test.config, d.Get("ttl"), test.ttl)
+		}
+	}
+}
+
+func TestRecordNamesFromId(t *testing.T) {
+	domainName, subName, recordType, value, err := recordNamesFromId("example.com/@/TXT/v=spf1 include:a/b -all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domainName != "example.com" || subName != "" || recordType != "TXT" || value != "v=spf1 include:a/b -all" {
+		t.Fatalf("unexpected result %q %q %q %q", domainName, subName, recordType, value)
+	}
+}
@@ -79,17 +79,21 @@ func resourceRRSet() *schema.Resource {
 
 func resourceRRSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	conf := m.(*DesecConfig)
-	conf.cache.Clear()
-	c := conf.client
 
 	var diags diag.Diagnostics
 
 	r := schemaToRRset(d)
-	rrset, err := c.Records.Create(ctx, r)
+	rrset, err := conf.batcher.Create(ctx, r)
 	if err != nil {
+		conf.cache.Invalidate(r.Domain)
 		return diag.FromErr(err)
 	}
+	if rrset == nil {
+		conf.cache.Invalidate(r.Domain)
+		return diag.Errorf("creating rrset %s returned no result", idFromNames(r.Domain, r.SubName, r.Type))
+	}
 
+	conf.cache.Put(*rrset)
 	rrsetIntoSchema(rrset, d)
 	return diags
 }
@@ -116,8 +120,6 @@ func resourceRRSetRead(ctx context.Context, d *schema.ResourceData, m interface{
 
 func resourceRRSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	conf := m.(*DesecConfig)
-	conf.cache.Clear()
-	c := conf.client
 
 	domainName, subName, recordType, err := namesFromId(d.Id())
 	if err != nil {
@@ -127,33 +129,36 @@ func resourceRRSetUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 	var diags diag.Diagnostics
 
 	r := schemaToRRset(d)
-	rrset, err := c.Records.Update(ctx, domainName, subName, recordType, r)
+	rrset, err := conf.batcher.Update(ctx, domainName, subName, recordType, r)
 	if err != nil {
 		if isNotFoundError(err) {
+			conf.cache.Remove(d.Id())
 			d.SetId("")
 			return diags
 		}
+		conf.cache.Invalidate(domainName)
 		return diag.FromErr(err)
 	}
 
+	conf.cache.Put(*rrset)
 	rrsetIntoSchema(rrset, d)
 	return diags
 }
 
 func resourceRRSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	conf := m.(*DesecConfig)
-	conf.cache.Clear()
-	c := conf.client
 
 	domainName, subName, recordType, err := namesFromId(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	err = c.Records.Delete(ctx, domainName, subName, recordType)
+	err = conf.batcher.Delete(ctx, domainName, subName, recordType)
 	if err != nil && !isNotFoundError(err) {
+		conf.cache.Invalidate(domainName)
 		return diag.FromErr(err)
 	}
+	conf.cache.Remove(d.Id())
 
 	d.SetId("")
 
@@ -181,18 +186,24 @@ func schemaToRRset(d *schema.ResourceData) dsc.RRSet {
 		Type:    rtype,
 	}
 
-	recs := d.Get("records").(*schema.Set)
-	r.Records = make([]string, recs.Len())
-	for i, rec := range recs.List() {
+	r.Records = quoteRecordSet(rtype, d.Get("records").(*schema.Set).List())
+	r.TTL = d.Get("ttl").(int)
+
+	return r
+}
+
+// quoteRecordSet turns configured record values into the form expected by the
+// API, which wants TXT and SPF contents in quotes.
+func quoteRecordSet(rtype string, s []interface{}) []string {
+	result := make([]string, len(s))
+	for i, rec := range s {
 		if (rtype == "TXT" || rtype == "SPF") && rec.(string)[0] != '"' {
-			r.Records[i] = fmt.Sprintf("\"%s\"", rec.(string))
+			result[i] = fmt.Sprintf("\"%s\"", rec.(string))
 		} else {
-			r.Records[i] = rec.(string)
+			result[i] = rec.(string)
 		}
 	}
-	r.TTL = d.Get("ttl").(int)
-
-	return r
+	return result
 }
 
 func normalizeRecordSetInterface(s []interface{}) []string {
@@ -249,3 +260,18 @@ func namesFromId(id string) (string, string, string, error) {
 
 	return domainName, subName, recordType, nil
 }
+
+// subNameFromQName splits a fully qualified name into the subname part below
+// the given domain, the inverse of how the API builds an RRset's name.
+func subNameFromQName(qname, domainName string) (string, error) {
+	qname = strings.ToLower(strings.TrimSuffix(qname, "."))
+	domainName = strings.ToLower(strings.TrimSuffix(domainName, "."))
+
+	if qname == domainName {
+		return "", nil
+	}
+	if !strings.HasSuffix(qname, "."+domainName) {
+		return "", fmt.Errorf("name %q is not within domain %q", qname, domainName)
+	}
+	return strings.TrimSuffix(qname, "."+domainName), nil
+}
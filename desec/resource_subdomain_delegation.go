@@ -0,0 +1,310 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+var absoluteNamePattern = regexp.MustCompile(`^([^.]+\.)+$`)
+
+type delegationNameserver struct {
+	hostname  string
+	addresses []string
+}
+
+/* Implementation notes:
+ *  - The ID is "domain/subname"
+ *  - The resource owns the NS RRset at subname, and the A/AAAA RRsets of nameservers within
+ *    the delegated subdomain. Nameservers elsewhere don't get glue, so addresses for them are
+ *    rejected instead of silently ignored.
+ *  - Every change is a single bulk request. Glue of removed nameservers is deleted in the same
+ *    request as the NS change, so it is never left behind.
+ */
+func resourceSubdomainDelegation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSubdomainDelegationCreate,
+		ReadContext:   resourceSubdomainDelegationRead,
+		UpdateContext: resourceSubdomainDelegationUpdate,
+		DeleteContext: resourceSubdomainDelegationDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"subname": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringLenBetween(1, 178),
+			},
+			"nameserver": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						// written in lowercase, so mixed case would show a diff on every plan
+						"hostname": {
+							Type:     schema.TypeString,
+							Required: true,
+							ValidateFunc: validation.All(
+								validation.StringMatch(absoluteNamePattern, "must be a fully qualified name with a trailing dot"),
+								validation.StringDoesNotMatch(regexp.MustCompile(`[A-Z]`), "must be lowercase"),
+							),
+						},
+						"addresses": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.IsIPAddress,
+							},
+						},
+					},
+				},
+			},
+			"ttl": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3600,
+				ValidateFunc: validation.IntBetween(60, 604800),
+			},
+		},
+	}
+}
+
+func resourceSubdomainDelegationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("domain").(string), d.Get("subname").(string)))
+
+	diags := resourceSubdomainDelegationApply(ctx, d, m, nil)
+	if diags.HasError() {
+		d.SetId("")
+	}
+	return diags
+}
+
+func resourceSubdomainDelegationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var diags diag.Diagnostics
+
+	domainName, subName, err := subdomainDelegationNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ns, err := conf.cache.GetRRSetById(ctx, c, idFromNames(domainName, subName, "NS"))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if ns == nil {
+		d.SetId("")
+		return diags
+	}
+
+	nameservers := make([]interface{}, 0, len(ns.Records))
+	for _, hostname := range ns.Records {
+		addresses := []string{}
+		if glueSubName, ok := glueSubName(hostname, domainName, subName); ok {
+			for _, recordType := range []string{"A", "AAAA"} {
+				glue, err := conf.cache.GetRRSetById(ctx, c, idFromNames(domainName, glueSubName, recordType))
+				if err != nil {
+					return diag.FromErr(err)
+				}
+				if glue != nil {
+					addresses = append(addresses, glue.Records...)
+				}
+			}
+		}
+		nameservers = append(nameservers, map[string]interface{}{
+			"hostname":  hostname,
+			"addresses": addresses,
+		})
+	}
+
+	d.Set("domain", domainName)
+	d.Set("subname", subName)
+	d.Set("ttl", ns.TTL)
+	d.Set("nameserver", nameservers)
+	return diags
+}
+
+func resourceSubdomainDelegationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	old, _ := d.GetChange("nameserver")
+	return resourceSubdomainDelegationApply(ctx, d, m, schemaToNameservers(old.(*schema.Set)))
+}
+
+func resourceSubdomainDelegationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName, subName, err := subdomainDelegationNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rrsets, err := subdomainDelegationRRSets(domainName, subName, schemaToNameservers(d.Get("nameserver").(*schema.Set)), 0)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	list := make([]dsc.RRSet, 0, len(rrsets))
+	for _, r := range rrsets {
+		list = append(list, r)
+	}
+	err = c.Records.BulkDelete(ctx, domainName, list)
+	if err != nil && !isNotFoundError(err) {
+		conf.cache.Invalidate(domainName)
+		return diag.FromErr(err)
+	}
+	for id := range rrsets {
+		conf.cache.Remove(id)
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceSubdomainDelegationApply writes the NS and glue RRsets, and deletes
+// the glue of the previous nameservers that is no longer needed.
+func resourceSubdomainDelegationApply(ctx context.Context, d *schema.ResourceData, m interface{}, previous []delegationNameserver) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName, subName, err := subdomainDelegationNamesFromId(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	desired, err := subdomainDelegationRRSets(domainName, subName, schemaToNameservers(d.Get("nameserver").(*schema.Set)), d.Get("ttl").(int))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	old, err := subdomainDelegationRRSets(domainName, subName, previous, 0)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	changes := make([]dsc.RRSet, 0, len(desired)+len(old))
+	for _, r := range desired {
+		changes = append(changes, dsc.RRSet{SubName: r.SubName, Type: r.Type, Records: r.Records, TTL: r.TTL})
+	}
+	for id, r := range old {
+		if _, ok := desired[id]; !ok {
+			changes = append(changes, dsc.RRSet{SubName: r.SubName, Type: r.Type, Records: []string{}})
+		}
+	}
+
+	results, err := c.Records.BulkUpdate(ctx, dsc.FullResource, domainName, changes)
+	if err != nil {
+		conf.cache.Invalidate(domainName)
+		return diag.FromErr(err)
+	}
+	for _, r := range changes {
+		if len(r.Records) == 0 {
+			conf.cache.Remove(idFromNames(domainName, r.SubName, r.Type))
+		}
+	}
+	for _, r := range results {
+		conf.cache.Put(r)
+	}
+
+	return resourceSubdomainDelegationRead(ctx, d, m)
+}
+
+// subdomainDelegationRRSets builds the NS RRset and the glue RRsets for a set
+// of nameservers, keyed by RRset id. Glue RRsets without addresses are left
+// out.
+func subdomainDelegationRRSets(domainName, subName string, nameservers []delegationNameserver, ttl int) (map[string]dsc.RRSet, error) {
+	result := make(map[string]dsc.RRSet)
+	if len(nameservers) == 0 {
+		return result, nil
+	}
+
+	ns := dsc.RRSet{Domain: domainName, SubName: subName, Type: "NS", TTL: ttl}
+	for _, n := range nameservers {
+		ns.Records = append(ns.Records, n.hostname)
+
+		glueName, inBailiwick := glueSubName(n.hostname, domainName, subName)
+		if !inBailiwick {
+			if len(n.addresses) > 0 {
+				return nil, fmt.Errorf("nameserver %q is outside of %s.%s and doesn't take glue addresses", n.hostname, subName, domainName)
+			}
+			continue
+		}
+		if len(n.addresses) == 0 {
+			return nil, fmt.Errorf("nameserver %q is within %s.%s and needs glue addresses", n.hostname, subName, domainName)
+		}
+
+		for _, address := range n.addresses {
+			recordType := "AAAA"
+			if net.ParseIP(address).To4() != nil {
+				recordType = "A"
+			}
+			id := idFromNames(domainName, glueName, recordType)
+			glue, ok := result[id]
+			if !ok {
+				glue = dsc.RRSet{Domain: domainName, SubName: glueName, Type: recordType, TTL: ttl}
+			}
+			glue.Records = append(glue.Records, address)
+			result[id] = glue
+		}
+	}
+	for id, r := range result {
+		sort.Strings(r.Records)
+		result[id] = r
+	}
+	sort.Strings(ns.Records)
+	result[idFromNames(domainName, subName, "NS")] = ns
+	return result, nil
+}
+
+// glueSubName returns the subname of a nameserver's glue records, if the
+// nameserver is at or below the delegated name.
+func glueSubName(hostname, domainName, subName string) (string, bool) {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	delegated := strings.ToLower(subName + "." + domainName)
+	if hostname != delegated && !strings.HasSuffix(hostname, "."+delegated) {
+		return "", false
+	}
+	glueName, err := subNameFromQName(hostname, domainName)
+	return glueName, err == nil
+}
+
+func schemaToNameservers(s *schema.Set) []delegationNameserver {
+	result := make([]delegationNameserver, 0, s.Len())
+	for _, raw := range s.List() {
+		n := raw.(map[string]interface{})
+		ns := delegationNameserver{hostname: strings.ToLower(n["hostname"].(string))}
+		if addresses, ok := n["addresses"].(*schema.Set); ok {
+			for _, a := range addresses.List() {
+				ns.addresses = append(ns.addresses, a.(string))
+			}
+		}
+		result = append(result, ns)
+	}
+	return result
+}
+
+func subdomainDelegationNamesFromId(id string) (string, string, error) {
+	domainName, subName, ok := strings.Cut(id, "/")
+	if !ok || domainName == "" || subName == "" || subName == "@" {
+		return "", "", fmt.Errorf("invalid id %q specified, should be in format \"domainName/subName\" for import", id)
+	}
+	return domainName, subName, nil
+}
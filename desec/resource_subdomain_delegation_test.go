@@ -0,0 +1,71 @@
+package desec
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestSubdomainDelegationRRSets(t *testing.T) {
+	nameservers := []delegationNameserver{
+		{hostname: "ns1.lab.example.com.", addresses: []string{"192.0.2.1", "2001:db8::1"}},
+		{hostname: "ns2.lab.example.com.", addresses: []string{"192.0.2.2"}},
+		{hostname: "ns.other.example."},
+	}
+
+	rrsets, err := subdomainDelegationRRSets("example.com", "lab", nameservers, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"example.com/lab/NS":       {"ns.other.example.", "ns1.lab.example.com.", "ns2.lab.example.com."},
+		"example.com/ns1.lab/A":    {"192.0.2.1"},
+		"example.com/ns1.lab/AAAA": {"2001:db8::1"},
+		"example.com/ns2.lab/A":    {"192.0.2.2"},
+	}
+	if len(rrsets) != len(expected) {
+		t.Fatalf("expected %d RRsets, got %v", len(expected), rrsets)
+	}
+	for id, records := range expected {
+		r, ok := rrsets[id]
+		if !ok {
+			t.Errorf("missing RRset %s", id)
+			continue
+		}
+		if !reflect.DeepEqual(r.Records, records) || r.TTL != 3600 {
+			t.Errorf("RRset %s: expected %v, got %v with TTL %d", id, records, r.Records, r.TTL)
+		}
+	}
+}
+
+func TestSubdomainDelegationRRSetsGlueErrors(t *testing.T) {
+	cases := map[string]delegationNameserver{
+		"missing glue":   {hostname: "ns1.lab.example.com."},
+		"needless glue":  {hostname: "ns.other.example.", addresses: []string{"192.0.2.1"}},
+		"sibling glue":   {hostname: "ns.example.com.", addresses: []string{"192.0.2.1"}},
+		"lookalike name": {hostname: "ns.xlab.example.com.", addresses: []string{"192.0.2.1"}},
+	}
+	for name, ns := range cases {
+		_, err := subdomainDelegationRRSets("example.com", "lab", []delegationNameserver{ns}, 3600)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSubdomainDelegationHostnameValidation(t *testing.T) {
+	validate := resourceSubdomainDelegation().Schema["nameserver"].Elem.(*schema.Resource).Schema["hostname"].ValidateFunc
+	for hostname, valid := range map[string]bool{
+		"ns1.example.com.": true,
+		"ns1.example.com":  false,
+		"NS1.example.com.": false,
+		"ns1.Example.com.": false,
+	} {
+		_, errs := validate(hostname, "hostname")
+		if (len(errs) == 0) != valid {
+			t.Errorf("%q: got errors %v, want valid %t", hostname, errs, valid)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package desec
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -18,6 +20,7 @@ func resourceToken() *schema.Resource {
 		ReadContext:   resourceTokenRead,
 		UpdateContext: resourceTokenUpdate,
 		DeleteContext: resourceTokenDelete,
+		CustomizeDiff: resourceTokenCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -47,8 +50,29 @@ func resourceToken() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
-			// Typically only available right after creation, emptied on next refresh
+			// Only returned on creation, kept in the state from then on
 			"token": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"pgp_key": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if _, _, err := encryptWithPGPKey(v.(string), ""); err != nil {
+						return nil, []error{fmt.Errorf("%s: %w", k, err)}
+					}
+					return nil, nil
+				},
+			},
+			// The token encrypted for pgp_key, instead of token
+			"encrypted_token": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"key_fingerprint": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
@@ -81,8 +105,47 @@ func resourceToken() *schema.Resource {
 				Optional: true,
 				Computed: true,
 			},
+			"rotate_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+			},
+			"keepers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			// When rotate_after is due, replaced once it has passed
+			"rotation_due": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"max_age": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateDuration,
+				DiffSuppressFunc: durationsEqual,
+			},
+			"max_unused_period": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validateDuration,
+				DiffSuppressFunc: durationsEqual,
+			},
+			"last_used": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"is_valid": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
 			"allowed_subnets": {
-				Type: schema.TypeList,
+				Type: schema.TypeSet,
+				Set:  allowedSubnetHash,
 				Elem: &schema.Schema{
 					Type:         schema.TypeString,
 					ValidateFunc: validation.IsCIDR,
@@ -101,15 +164,55 @@ func resourceTokenCreate(ctx context.Context, d *schema.ResourceData, m interfac
 	var diags diag.Diagnostics
 
 	t := schemaToToken(d)
+	if isRotatedToken(d) {
+		if err := conf.rotations.CheckPredecessor(t.Name); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	token, err := c.Tokens.Create(ctx, t.Name)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	d.SetId(token.ID)
+
+	if pgpKey := d.Get("pgp_key").(string); pgpKey != "" {
+		encrypted, fingerprint, err := encryptWithPGPKey(pgpKey, token.Value)
+		if err != nil {
+			// the secret is lost, so the token is of no use
+			if err := c.Tokens.Delete(ctx, token.ID); err != nil {
+				log.Printf("[WARN] failed to delete token %s: %s", token.ID, err)
+			}
+			d.SetId("")
+			return diag.FromErr(err)
+		}
+		d.Set("encrypted_token", encrypted)
+		d.Set("key_fingerprint", fingerprint)
+	} else {
+		d.Set("token", token.Value)
+	}
+
 	// TODO unify in create call
 	token, err = c.Tokens.Update(ctx, token.ID, &t)
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if d.Get("max_age").(string) != "" || d.Get("max_unused_period").(string) != "" {
+		var lifetimes *tokenLifetimes
+		token, lifetimes, err = updateTokenLifetimes(ctx, conf, token.ID, d)
+		if err != nil {
+			return apiErrorDiagnostics(err)
+		}
+		if err := tokenLifetimesIntoSchema(lifetimes, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if isRotatedToken(d) {
+		diags = append(diags, conf.rotations.Created(ctx, c, token)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
 
 	tokenIntoSchema(token, d)
 	return diags
@@ -117,11 +220,11 @@ func resourceTokenCreate(ctx context.Context, d *schema.ResourceData, m interfac
 
 func resourceTokenRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	conf := m.(*DesecConfig)
-	c := conf.client
 
 	var diags diag.Diagnostics
 
-	t, err := c.Tokens.Get(ctx, d.Id())
+	// the client doesn't know about the lifetimes
+	t, lifetimes, err := getTokenWithLifetimes(ctx, conf, d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -130,6 +233,9 @@ func resourceTokenRead(ctx context.Context, d *schema.ResourceData, m interface{
 		d.SetId("")
 	} else {
 		tokenIntoSchema(t, d)
+		if err := tokenLifetimesIntoSchema(lifetimes, d); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
 	return diags
@@ -150,6 +256,16 @@ func resourceTokenUpdate(ctx context.Context, d *schema.ResourceData, m interfac
 		}
 		return diag.FromErr(err)
 	}
+	if d.HasChanges("max_age", "max_unused_period") {
+		var lifetimes *tokenLifetimes
+		token, lifetimes, err = updateTokenLifetimes(ctx, conf, d.Id(), d)
+		if err != nil {
+			return apiErrorDiagnostics(err)
+		}
+		if err := tokenLifetimesIntoSchema(lifetimes, d); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	tokenIntoSchema(token, d)
 
 	return diags
@@ -159,6 +275,12 @@ func resourceTokenDelete(ctx context.Context, d *schema.ResourceData, m interfac
 	conf := m.(*DesecConfig)
 	c := conf.client
 
+	if isRotatedToken(d) {
+		if err := conf.rotations.Deleting(ctx, c, d.Id(), d.Get("name").(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	err := c.Tokens.Delete(ctx, d.Id())
 	if err != nil && !isNotFoundError(err) {
 		return diag.FromErr(err)
@@ -169,6 +291,73 @@ func resourceTokenDelete(ctx context.Context, d *schema.ResourceData, m interfac
 	return nil
 }
 
+// resourceTokenCustomizeDiff plans a replacement of the token once rotate_after
+// has passed since its creation.
+func resourceTokenCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	due, err := tokenRotationDue(d.Get("created").(string), d.Get("rotate_after").(string))
+	if err != nil {
+		return err
+	}
+	if due != nil && !time.Now().Before(*due) {
+		if err := d.SetNewComputed("rotation_due"); err != nil {
+			return err
+		}
+		return d.ForceNew("rotation_due")
+	}
+
+	dueString := ""
+	if due != nil {
+		dueString = due.Format(time.RFC3339)
+	}
+	if d.Get("rotation_due").(string) != dueString {
+		return d.SetNew("rotation_due", dueString)
+	}
+	return nil
+}
+
+// tokenRotationDue returns when a token created at the given time is due for
+// rotation, or nil if it isn't rotated.
+func tokenRotationDue(created, rotateAfter string) (*time.Time, error) {
+	if rotateAfter == "" || created == "" {
+		return nil, nil
+	}
+	createdTime, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := parseDuration(rotateAfter)
+	if err != nil {
+		return nil, err
+	}
+	due := createdTime.Add(duration)
+	return &due, nil
+}
+
+// isRotatedToken returns whether the token carries its policies over to its
+// replacement, see tokenRotations.
+func isRotatedToken(d *schema.ResourceData) bool {
+	return d.Get("rotate_after").(string) != "" || len(d.Get("keepers").(map[string]interface{})) > 0
+}
+
+func isDefaultTokenPolicy(p dsc.TokenPolicy) bool {
+	return p.Domain == nil && p.SubName == nil && p.Type == nil
+}
+
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	duration, err := parseDuration(v.(string))
+	if err != nil {
+		return nil, []error{fmt.Errorf("%s: %w, expected a duration such as \"90d\" or \"2160h\"", k, err)}
+	}
+	if duration <= 0 {
+		return nil, []error{fmt.Errorf("%s: must be positive", k)}
+	}
+	return nil, nil
+}
+
 func tokenIntoSchema(r *dsc.Token, d *schema.ResourceData) {
 	d.SetId(r.ID)
 	d.Set("created", (*r.Created).Format(time.RFC3339))
@@ -178,7 +367,19 @@ func tokenIntoSchema(r *dsc.Token, d *schema.ResourceData) {
 	d.Set("perm_delete_domain", r.PermDeleteDomain)
 	d.Set("perm_manage_tokens", r.PermManageTokens)
 	d.Set("auto_policy", r.AutoPolicy)
-	d.Set("token", r.Value)
+	d.Set("is_valid", r.IsValid)
+	if r.LastUsed != nil {
+		d.Set("last_used", r.LastUsed.Format(time.RFC3339))
+	} else {
+		d.Set("last_used", "")
+	}
+	// the secret is only returned on creation, see resourceTokenCreate
+	due, _ := tokenRotationDue(d.Get("created").(string), d.Get("rotate_after").(string))
+	if due != nil {
+		d.Set("rotation_due", due.Format(time.RFC3339))
+	} else {
+		d.Set("rotation_due", "")
+	}
 	if r.AllowedSubnets != nil {
 		d.Set("allowed_subnets", r.AllowedSubnets)
 	}
@@ -194,7 +395,7 @@ func schemaToToken(d *schema.ResourceData) dsc.Token {
 		AutoPolicy:       d.Get("auto_policy").(bool),
 	}
 	result.AllowedSubnets = []string{}
-	for _, as := range d.Get("allowed_subnets").([]any) {
+	for _, as := range d.Get("allowed_subnets").(*schema.Set).List() {
 		result.AllowedSubnets = append(result.AllowedSubnets, as.(string))
 	}
 	return result
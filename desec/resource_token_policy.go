@@ -70,7 +70,17 @@ func resourceTokenPolicyCreate(ctx context.Context, d *schema.ResourceData, m in
 	var diags diag.Diagnostics
 
 	tokenId := d.Get("token_id").(string)
-	tokenPolicy, err := c.TokenPolicies.Create(ctx, tokenId, schemaToTokenPolicy(d))
+	want := schemaToTokenPolicy(d)
+
+	// a rotated token may start out with copies of its predecessor's
+	// policies, which are adopted instead of conflicting with them
+	var tokenPolicy *dsc.TokenPolicy
+	var err error
+	if copied := conf.rotations.Adopt(tokenId, want); copied != nil {
+		tokenPolicy, err = c.TokenPolicies.Update(ctx, tokenId, copied.ID, want)
+	} else {
+		tokenPolicy, err = c.TokenPolicies.Create(ctx, tokenId, want)
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -165,6 +175,20 @@ func tokenPolicyIntoSchema(tokenId string, r *dsc.TokenPolicy, d *schema.Resourc
 	d.Set("perm_write", r.WritePermission)
 }
 
+// findTokenPolicy returns the policy for the same domain, subname and type, if
+// there is one.
+func findTokenPolicy(policies []dsc.TokenPolicy, want dsc.TokenPolicy) *dsc.TokenPolicy {
+	same := func(a, b *string) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	for i, p := range policies {
+		if same(p.Domain, want.Domain) && same(p.SubName, want.SubName) && same(p.Type, want.Type) {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
 func schemaToTokenPolicy(d *schema.ResourceData) dsc.TokenPolicy {
 	result := dsc.TokenPolicy{
 		WritePermission: d.Get("perm_write").(bool),
@@ -0,0 +1,91 @@
+package desec
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestTokenRotationDue(t *testing.T) {
+	due, err := tokenRotationDue("2024-01-01T00:00:00Z", "2160h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due == nil || !due.Equal(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected rotation after 90 days, got %v", due)
+	}
+
+	due, err = tokenRotationDue("2024-01-01T00:00:00Z", "")
+	if err != nil || due != nil {
+		t.Fatalf("expected no rotation, got %v, %v", due, err)
+	}
+}
+
+func TestTokenCustomizeDiffRotation(t *testing.T) {
+	created := time.Now().UTC().Add(-100 * 24 * time.Hour).Format(time.RFC3339)
+	state := func(rotationDue string) *terraform.InstanceState {
+		return &terraform.InstanceState{
+			ID: "token",
+			Attributes: map[string]string{
+				"id":           "token",
+				"name":         "ci",
+				"created":      created,
+				"rotate_after": "90d",
+				"rotation_due": rotationDue,
+			},
+		}
+	}
+
+	tests := []struct {
+		rotateAfter string
+		replace     bool
+	}{
+		{rotateAfter: "90d", replace: true},
+		{rotateAfter: "200d", replace: false},
+		{rotateAfter: "", replace: false},
+	}
+	for _, test := range tests {
+		config := map[string]interface{}{"name": "ci"}
+		if test.rotateAfter != "" {
+			config["rotate_after"] = test.rotateAfter
+		}
+		diff, err := resourceToken().Diff(context.Background(), state("2024-01-01T00:00:00Z"), terraform.NewResourceConfigRaw(config), nil)
+		if err != nil {
+			t.Fatalf("rotate_after %q: %s", test.rotateAfter, err)
+		}
+		if replace := diff != nil && diff.RequiresNew(); replace != test.replace {
+			t.Errorf("rotate_after %q: got replacement %t, want %t", test.rotateAfter, replace, test.replace)
+		}
+		if test.replace && !diff.Attributes["rotation_due"].NewComputed {
+			t.Errorf("rotate_after %q: expected rotation_due to be unknown until the replacement", test.rotateAfter)
+		}
+	}
+}
+
+func TestFindTokenPolicy(t *testing.T) {
+	domain := "example.com"
+	subName := "www"
+	policies := []dsc.TokenPolicy{
+		{ID: "default"},
+		{ID: "domain", Domain: &domain},
+		{ID: "subname", Domain: &domain, SubName: &subName},
+	}
+
+	other := "example.org"
+	cases := map[string]dsc.TokenPolicy{
+		"default": {},
+		"domain":  {Domain: &domain, WritePermission: true},
+		"subname": {Domain: &domain, SubName: &subName},
+		"":        {Domain: &other},
+	}
+	for id, want := range cases {
+		found := findTokenPolicy(policies, want)
+		if id == "" && found != nil || id != "" && (found == nil || found.ID != id) {
+			t.Errorf("expected %q, got %v", id, found)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package desec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// RRsets that deSEC manages on its own, and which desec_zone never touches.
+var zoneServerManagedRRSets = []string{"@/NS"}
+
+var zoneExcludePattern = regexp.MustCompile(`^[^/]*/[A-Z0-9]+$`)
+
+/* Implementation notes:
+ *  - The ID is the domain name
+ *  - Every RRset of the domain that is not excluded is owned by this resource. Read reports
+ *    all of them, so RRsets created outside of terraform show up as a diff and get deleted.
+ *  - All changes for an apply are sent as one bulk request, so they land atomically.
+ */
+func resourceZone() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceZoneCreate,
+		ReadContext:   resourceZoneRead,
+		UpdateContext: resourceZoneUpdate,
+		DeleteContext: resourceZoneDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"domain": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"exclude": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(zoneExcludePattern, "must be in format \"subName/type\""),
+				},
+			},
+			"rrset": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Set:      zoneRRSetHash,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"subname": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringLenBetween(0, 178),
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"records": {
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ttl": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(60, 604800),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceZoneCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	d.SetId(d.Get("domain").(string))
+	return resourceZoneApply(ctx, d, m)
+}
+
+func resourceZoneRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	var diags diag.Diagnostics
+
+	rrsets, err := conf.cache.GetRRSetsByDomain(ctx, c, d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if rrsets == nil {
+		d.SetId("")
+		return diags
+	}
+
+	excluded := zoneExclusions(d)
+	owned := make([]interface{}, 0, len(rrsets))
+	for _, r := range rrsets {
+		if excluded[idFromNames(r.Domain, r.SubName, r.Type)] {
+			continue
+		}
+		owned = append(owned, map[string]interface{}{
+			"subname": r.SubName,
+			"type":    r.Type,
+			"records": normalizeRecordSet(r.Records),
+			"ttl":     r.TTL,
+		})
+	}
+
+	d.Set("domain", d.Id())
+	d.Set("rrset", owned)
+	return diags
+}
+
+func resourceZoneUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceZoneApply(ctx, d, m)
+}
+
+func resourceZoneDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName := d.Id()
+	desired, err := schemaToZoneRRSets(domainName, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if len(desired) > 0 {
+		rrsets := make([]dsc.RRSet, 0, len(desired))
+		for _, r := range desired {
+			rrsets = append(rrsets, r)
+		}
+		err = c.Records.BulkDelete(ctx, domainName, rrsets)
+		if err != nil && !isNotFoundError(err) {
+			conf.cache.Invalidate(domainName)
+			return diag.FromErr(err)
+		}
+		for id := range desired {
+			conf.cache.Remove(id)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceZoneApply(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	conf := m.(*DesecConfig)
+	c := conf.client
+
+	domainName := d.Id()
+	desired, err := schemaToZoneRRSets(domainName, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	live, err := conf.cache.GetRRSetsByDomain(ctx, c, domainName)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if live == nil {
+		return diag.Errorf("domain %q does not exist", domainName)
+	}
+
+	changes := zoneChanges(live, desired, zoneExclusions(d))
+	if len(changes) > 0 {
+		results, err := c.Records.BulkUpdate(ctx, dsc.FullResource, domainName, changes)
+		if err != nil {
+			conf.cache.Invalidate(domainName)
+			return diag.FromErr(err)
+		}
+		for _, r := range changes {
+			if len(r.Records) == 0 {
+				conf.cache.Remove(idFromNames(domainName, r.SubName, r.Type))
+			}
+		}
+		for _, r := range results {
+			conf.cache.Put(r)
+		}
+	}
+
+	return resourceZoneRead(ctx, d, m)
+}
+
+// zoneChanges computes the RRsets to send in a bulk PUT request so that the
+// live zone matches the desired one. RRsets to delete are sent without records.
+func zoneChanges(live []dsc.RRSet, desired map[string]dsc.RRSet, excluded map[string]bool) []dsc.RRSet {
+	var changes []dsc.RRSet
+
+	liveById := make(map[string]dsc.RRSet)
+	for _, r := range live {
+		id := idFromNames(r.Domain, r.SubName, r.Type)
+		if excluded[id] {
+			continue
+		}
+		liveById[id] = r
+		if _, ok := desired[id]; !ok {
+			changes = append(changes, dsc.RRSet{
+				SubName: r.SubName,
+				Type:    r.Type,
+				Records: []string{},
+			})
+		}
+	}
+
+	for id, want := range desired {
+		have, ok := liveById[id]
+		if ok && have.TTL == want.TTL && reflect.DeepEqual(normalizeRecordSet(have.Records), normalizeRecordSet(want.Records)) {
+			continue
+		}
+		changes = append(changes, dsc.RRSet{
+			SubName: want.SubName,
+			Type:    want.Type,
+			Records: want.Records,
+			TTL:     want.TTL,
+		})
+	}
+
+	return changes
+}
+
+func schemaToZoneRRSets(domainName string, d *schema.ResourceData) (map[string]dsc.RRSet, error) {
+	excluded := zoneExclusions(d)
+
+	result := make(map[string]dsc.RRSet)
+	for _, raw := range d.Get("rrset").(*schema.Set).List() {
+		rr := raw.(map[string]interface{})
+		r := dsc.RRSet{
+			Domain:  domainName,
+			SubName: rr["subname"].(string),
+			Type:    rr["type"].(string),
+			TTL:     rr["ttl"].(int),
+		}
+		r.Records = quoteRecordSet(r.Type, rr["records"].(*schema.Set).List())
+
+		id := idFromNames(r.Domain, r.SubName, r.Type)
+		if excluded[id] {
+			return nil, fmt.Errorf("rrset %q is excluded from this zone and can't be declared in it", id)
+		}
+		if _, ok := result[id]; ok {
+			return nil, fmt.Errorf("rrset %q is declared more than once", id)
+		}
+		result[id] = r
+	}
+	return result, nil
+}
+
+// zoneExclusions returns the set of RRset ids not owned by the zone resource.
+// Entries match exactly, a "*" subname is the wildcard owner and not a pattern.
+func zoneExclusions(d *schema.ResourceData) map[string]bool {
+	domainName := d.Get("domain").(string)
+	if domainName == "" {
+		domainName = d.Id()
+	}
+
+	patterns := append([]string{}, zoneServerManagedRRSets...)
+	for _, e := range d.Get("exclude").(*schema.Set).List() {
+		patterns = append(patterns, e.(string))
+	}
+
+	result := make(map[string]bool)
+	for _, p := range patterns {
+		subName, recordType, _ := strings.Cut(p, "/")
+		if subName == "@" {
+			subName = ""
+		}
+		result[idFromNames(domainName, subName, recordType)] = true
+	}
+	return result
+}
+
+func zoneRRSetHash(v interface{}) int {
+	rr := v.(map[string]interface{})
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("%s/%s/%d", rr["subname"].(string), rr["type"].(string), rr["ttl"].(int)))
+	for _, rec := range normalizeRecordSetInterface(rr["records"].(*schema.Set).List()) {
+		buf.WriteString("\n" + rec)
+	}
+	return schema.HashString(buf.String())
+}
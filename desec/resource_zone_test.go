@@ -0,0 +1,177 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestAccDesecZoneBasic(t *testing.T) {
+	uuid, err := uuid.GenerateUUID()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	domainName := fmt.Sprintf("%s.example", uuid)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckDesecDomainDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckDesecZoneConfigBasic(domainName, "[ \"127.0.0.1\" ]"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("desec_zone.desec-example", "domain", domainName),
+					resource.TestCheckResourceAttr("desec_zone.desec-example", "rrset.#", "2"),
+				),
+			},
+			{
+				// an RRset created outside of the zone resource is planned for deletion
+				PreConfig: func() { testAccCreateUnmanagedRRSet(t, domainName) },
+				Config:    testAccCheckDesecZoneConfigBasic(domainName, "[ \"127.0.0.1\", \"127.0.0.2\" ]"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("desec_zone.desec-example", "rrset.#", "2"),
+					testAccCheckDesecZoneUnmanagedRRSetGone,
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckDesecZoneConfigBasic(domainName, ips string) string {
+	return fmt.Sprintf(`
+	resource "desec_domain" "desec-example" {
+		name = "%s"
+	}
+	resource "desec_zone" "desec-example" {
+		domain = desec_domain.desec-example.name
+		rrset {
+			subname = "test"
+			type = "A"
+			records = %s
+			ttl = 3600
+		}
+		rrset {
+			subname = ""
+			type = "TXT"
+			records = ["one", "two"]
+			ttl = 3600
+		}
+	}
+	`, domainName, ips)
+}
+
+func testAccCreateUnmanagedRRSet(t *testing.T, domainName string) {
+	c := testAccProvider.Meta().(*DesecConfig).client
+
+	_, err := c.Records.Create(context.TODO(), dsc.RRSet{
+		Domain:  domainName,
+		SubName: "unmanaged",
+		Type:    "A",
+		Records: []string{"127.0.0.9"},
+		TTL:     3600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testAccCheckDesecZoneUnmanagedRRSetGone(s *terraform.State) error {
+	c := testAccProvider.Meta().(*DesecConfig).client
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "desec_zone" {
+			continue
+		}
+
+		_, err := c.Records.Get(context.TODO(), rs.Primary.ID, "unmanaged", "A")
+		if err == nil {
+			return fmt.Errorf("unmanaged RRset still exists in %s", rs.Primary.ID)
+		}
+		if !isNotFoundError(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestZoneExclusions(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceZone().Schema, map[string]interface{}{
+		"domain":  "example.com",
+		"exclude": []interface{}{"_acme-challenge/TXT", "@/CAA", "*/TXT"},
+	})
+
+	got := zoneExclusions(d)
+	want := map[string]bool{
+		"example.com/@/NS":                true,
+		"example.com/_acme-challenge/TXT": true,
+		"example.com/@/CAA":               true,
+		"example.com/*/TXT":               true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestZoneChanges(t *testing.T) {
+	live := []dsc.RRSet{
+		{Domain: "example.com", SubName: "", Type: "NS", Records: []string{"ns1.desec.io."}, TTL: 3600},
+		{Domain: "example.com", SubName: "same", Type: "A", Records: []string{"127.0.0.2", "127.0.0.1"}, TTL: 3600},
+		{Domain: "example.com", SubName: "records", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600},
+		{Domain: "example.com", SubName: "ttl", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600},
+		{Domain: "example.com", SubName: "stale", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600},
+		{Domain: "example.com", SubName: "_acme-challenge", Type: "TXT", Records: []string{"\"token\""}, TTL: 60},
+		{Domain: "example.com", SubName: "*", Type: "TXT", Records: []string{"\"wildcard\""}, TTL: 60},
+		{Domain: "example.com", SubName: "www", Type: "TXT", Records: []string{"\"other\""}, TTL: 60},
+	}
+	desired := map[string]dsc.RRSet{
+		"example.com/same/A":    {Domain: "example.com", SubName: "same", Type: "A", Records: []string{"127.0.0.1", "127.0.0.2"}, TTL: 3600},
+		"example.com/records/A": {Domain: "example.com", SubName: "records", Type: "A", Records: []string{"127.0.0.3"}, TTL: 3600},
+		"example.com/ttl/A":     {Domain: "example.com", SubName: "ttl", Type: "A", Records: []string{"127.0.0.1"}, TTL: 60},
+		"example.com/new/AAAA":  {Domain: "example.com", SubName: "new", Type: "AAAA", Records: []string{"::1"}, TTL: 3600},
+	}
+	excluded := map[string]bool{
+		"example.com/@/NS":                true,
+		"example.com/_acme-challenge/TXT": true,
+		"example.com/*/TXT":               true,
+	}
+
+	changes := zoneChanges(live, desired, excluded)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].SubName+"/"+changes[i].Type < changes[j].SubName+"/"+changes[j].Type
+	})
+
+	// exclusions match exactly, so the wildcard exclusion keeps www/TXT owned by the zone
+	want := []dsc.RRSet{
+		{SubName: "new", Type: "AAAA", Records: []string{"::1"}, TTL: 3600},
+		{SubName: "records", Type: "A", Records: []string{"127.0.0.3"}, TTL: 3600},
+		{SubName: "stale", Type: "A", Records: []string{}},
+		{SubName: "ttl", Type: "A", Records: []string{"127.0.0.1"}, TTL: 60},
+		{SubName: "www", Type: "TXT", Records: []string{}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %+v, want %+v", changes, want)
+	}
+}
+
+func TestZoneChangesNone(t *testing.T) {
+	live := []dsc.RRSet{
+		{Domain: "example.com", SubName: "", Type: "NS", Records: []string{"ns1.desec.io."}, TTL: 3600},
+	}
+	excluded := map[string]bool{"example.com/@/NS": true}
+
+	if changes := zoneChanges(live, map[string]dsc.RRSet{}, excluded); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
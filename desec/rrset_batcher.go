@@ -0,0 +1,239 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+type rrsetOpKind int
+
+const (
+	rrsetOpCreate rrsetOpKind = iota
+	rrsetOpUpdate
+	rrsetOpDelete
+)
+
+type rrsetOp struct {
+	kind  rrsetOpKind
+	rrset dsc.RRSet
+
+	// set once the operation went through
+	done   bool
+	result *dsc.RRSet
+	err    error
+}
+
+type rrsetBatch struct {
+	ops  map[string]*rrsetOp
+	done chan struct{}
+}
+
+// RRSetBatcher coalesces RRset writes for the same domain that arrive within
+// a short window into bulk requests, and hands each caller its own result.
+type RRSetBatcher struct {
+	client  *dsc.Client
+	window  time.Duration
+	mutex   sync.Mutex
+	pending map[string]*rrsetBatch
+}
+
+func NewRRSetBatcher(c *dsc.Client, window time.Duration) RRSetBatcher {
+	return RRSetBatcher{c, window, sync.Mutex{}, make(map[string]*rrsetBatch)}
+}
+
+func (b *RRSetBatcher) Create(ctx context.Context, rrset dsc.RRSet) (*dsc.RRSet, error) {
+	return b.submit(ctx, rrsetOpCreate, rrset)
+}
+
+func (b *RRSetBatcher) Update(ctx context.Context, domainName, subName, recordType string, rrset dsc.RRSet) (*dsc.RRSet, error) {
+	rrset.Domain = domainName
+	rrset.SubName = subName
+	rrset.Type = recordType
+	return b.submit(ctx, rrsetOpUpdate, rrset)
+}
+
+func (b *RRSetBatcher) Delete(ctx context.Context, domainName, subName, recordType string) error {
+	_, err := b.submit(ctx, rrsetOpDelete, dsc.RRSet{Domain: domainName, SubName: subName, Type: recordType})
+	return err
+}
+
+func (b *RRSetBatcher) submit(ctx context.Context, kind rrsetOpKind, rrset dsc.RRSet) (*dsc.RRSet, error) {
+	op := &rrsetOp{kind: kind, rrset: rrset}
+	if b.window <= 0 {
+		b.run(ctx, op)
+		return op.result, op.err
+	}
+
+	domainName := rrset.Domain
+	id := idFromNames(rrset.Domain, rrset.SubName, rrset.Type)
+
+	var batch *rrsetBatch
+	for {
+		b.mutex.Lock()
+		batch = b.pending[domainName]
+		if batch != nil && batch.ops[id] != nil {
+			// a bulk request can't touch the same RRset twice, so wait for the next one
+			b.mutex.Unlock()
+			<-batch.done
+			continue
+		}
+		if batch == nil {
+			batch = &rrsetBatch{make(map[string]*rrsetOp), make(chan struct{})}
+			b.pending[domainName] = batch
+			flushCtx := context.WithoutCancel(ctx)
+			pending := batch
+			time.AfterFunc(b.window, func() {
+				b.flush(flushCtx, domainName, pending)
+			})
+		}
+		batch.ops[id] = op
+		b.mutex.Unlock()
+		break
+	}
+
+	<-batch.done
+	return op.result, op.err
+}
+
+func (b *RRSetBatcher) flush(ctx context.Context, domainName string, batch *rrsetBatch) {
+	b.mutex.Lock()
+	if b.pending[domainName] == batch {
+		delete(b.pending, domainName)
+	}
+	b.mutex.Unlock()
+
+	defer close(batch.done)
+
+	ids := make([]string, 0, len(batch.ops))
+	for id := range batch.ops {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	ops := make([]*rrsetOp, len(ids))
+	for i, id := range ids {
+		ops[i] = batch.ops[id]
+	}
+
+	// creates go out as their own request, so they fail on existing RRsets
+	// just like a single create does
+	var creates, changes []*rrsetOp
+	for _, op := range ops {
+		if op.kind == rrsetOpCreate {
+			creates = append(creates, op)
+		} else {
+			changes = append(changes, op)
+		}
+	}
+	b.runGroup(ctx, domainName, creates)
+	b.runGroup(ctx, domainName, changes)
+}
+
+// runGroup sends operations of the same request type, in bulk if there are
+// several.
+func (b *RRSetBatcher) runGroup(ctx context.Context, domainName string, ops []*rrsetOp) {
+	switch len(ops) {
+	case 0:
+		return
+	case 1:
+		b.run(ctx, ops[0])
+		return
+	}
+
+	err := b.runBulk(ctx, domainName, ops)
+	if err == nil {
+		return
+	}
+
+	apiError, ok := err.(*dsc.APIError)
+	for _, op := range ops {
+		if op.done {
+			continue
+		}
+		if ok && apiError.StatusCode == http.StatusBadRequest {
+			// bulk requests are rejected as a whole. retry one by one, so each
+			// resource gets to see its own error.
+			b.run(ctx, op)
+		} else {
+			op.err = err
+		}
+	}
+}
+
+func (b *RRSetBatcher) run(ctx context.Context, op *rrsetOp) {
+	c := b.client
+	r := op.rrset
+
+	switch op.kind {
+	case rrsetOpCreate:
+		op.result, op.err = c.Records.Create(ctx, r)
+	case rrsetOpUpdate:
+		op.result, op.err = c.Records.Update(ctx, r.Domain, r.SubName, r.Type, r)
+	case rrsetOpDelete:
+		op.err = c.Records.Delete(ctx, r.Domain, r.SubName, r.Type)
+	}
+	op.done = true
+}
+
+// runBulk sends operations of one kind as a bulk request, so they are applied
+// atomically. Creates are sent as a bulk POST, which fails for existing
+// RRsets, updates and deletes as a bulk PATCH.
+func (b *RRSetBatcher) runBulk(ctx context.Context, domainName string, ops []*rrsetOp) error {
+	rrsets := make([]dsc.RRSet, len(ops))
+	for i, op := range ops {
+		rrsets[i] = bulkRRSet(op)
+	}
+
+	var results []dsc.RRSet
+	var err error
+	if ops[0].kind == rrsetOpCreate {
+		results, err = b.client.Records.BulkCreate(ctx, domainName, rrsets)
+	} else {
+		results, err = b.client.Records.BulkUpdate(ctx, dsc.OnlyFields, domainName, rrsets)
+	}
+	if err != nil {
+		return err
+	}
+	assignBulkResults(ops, results)
+	return nil
+}
+
+func bulkRRSet(op *rrsetOp) dsc.RRSet {
+	r := dsc.RRSet{
+		SubName: op.rrset.SubName,
+		Type:    op.rrset.Type,
+		Records: op.rrset.Records,
+		TTL:     op.rrset.TTL,
+	}
+	if op.kind == rrsetOpDelete {
+		r.Records = []string{}
+		r.TTL = 0
+	}
+	return r
+}
+
+// assignBulkResults matches the RRsets returned by a bulk request to the
+// operations. RRsets that were deleted, also by an update without records, are
+// not part of the response, any other operation without a result fails.
+func assignBulkResults(ops []*rrsetOp, results []dsc.RRSet) {
+	byId := make(map[string]dsc.RRSet)
+	for _, r := range results {
+		byId[idFromNames(r.Domain, r.SubName, r.Type)] = r
+	}
+
+	for _, op := range ops {
+		id := idFromNames(op.rrset.Domain, op.rrset.SubName, op.rrset.Type)
+		r, ok := byId[id]
+		if ok {
+			op.result = &r
+		} else if op.kind != rrsetOpDelete && len(op.rrset.Records) > 0 {
+			op.err = fmt.Errorf("bulk response is missing rrset %s", id)
+		}
+		op.done = true
+	}
+}
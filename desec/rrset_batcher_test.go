@@ -0,0 +1,266 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestRRSetBatcherCoalesces(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		mutex.Lock()
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		mutex.Unlock()
+
+		var rrsets []dsc.RRSet
+		if err := json.Unmarshal(body, &rrsets); err != nil {
+			t.Errorf("expected a bulk request, got %s", body)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for i := range rrsets {
+			rrsets[i].Domain = "example.com"
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rrsets)
+	}))
+
+	batcher := NewRRSetBatcher(c, 50*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			subName := fmt.Sprintf("host%d", i)
+			r, err := batcher.Create(context.Background(), dsc.RRSet{
+				Domain:  "example.com",
+				SubName: subName,
+				Type:    "A",
+				Records: []string{"127.0.0.1"},
+				TTL:     3600,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if r == nil || r.SubName != subName {
+				t.Errorf("got result %v for %s", r, subName)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(requests) != 1 || requests[0] != "POST /domains/example.com/rrsets/" {
+		t.Fatalf("expected one bulk request, got %v", requests)
+	}
+}
+
+func TestRRSetBatcherRetriesRejectedBulk(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		var rrset dsc.RRSet
+		if err := json.Unmarshal(body, &rrset); err != nil {
+			// bulk request, reject as a whole
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if rrset.SubName == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		rrset.Domain = "example.com"
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rrset)
+	}))
+
+	batcher := NewRRSetBatcher(c, 50*time.Millisecond)
+
+	errs := make(map[string]error)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, subName := range []string{"good", "bad"} {
+		wg.Add(1)
+		go func(subName string) {
+			defer wg.Done()
+			_, err := batcher.Create(context.Background(), dsc.RRSet{
+				Domain:  "example.com",
+				SubName: subName,
+				Type:    "A",
+				Records: []string{"127.0.0.1"},
+				TTL:     3600,
+			})
+			mutex.Lock()
+			errs[subName] = err
+			mutex.Unlock()
+		}(subName)
+	}
+	wg.Wait()
+
+	if errs["good"] != nil {
+		t.Errorf("unexpected error for good RRset: %v", errs["good"])
+	}
+	if errs["bad"] == nil {
+		t.Errorf("expected error for bad RRset")
+	}
+}
+
+func TestRRSetBatcherBulkRequests(t *testing.T) {
+	var mutex sync.Mutex
+	var requests []string
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		mutex.Lock()
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		mutex.Unlock()
+
+		var rrsets []dsc.RRSet
+		if err := json.Unmarshal(body, &rrsets); err != nil {
+			t.Errorf("expected a bulk request, got %s", body)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// the response leaves out deleted RRsets, and here also the "lost" one
+		var result []dsc.RRSet
+		for _, r := range rrsets {
+			if len(r.Records) == 0 || r.SubName == "lost" {
+				continue
+			}
+			r.Domain = "example.com"
+			result = append(result, r)
+		}
+		if req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+
+	batcher := NewRRSetBatcher(c, 50*time.Millisecond)
+
+	rrset := func(subName string) dsc.RRSet {
+		return dsc.RRSet{Domain: "example.com", SubName: subName, Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600}
+	}
+
+	var wg sync.WaitGroup
+	var createErr, updateErr, clearErr, deleteErr, lostErr, lostCreateErr error
+	var created, updated, cleared *dsc.RRSet
+	wg.Add(6)
+	go func() {
+		defer wg.Done()
+		created, createErr = batcher.Create(context.Background(), rrset("new"))
+	}()
+	go func() {
+		defer wg.Done()
+		updated, updateErr = batcher.Update(context.Background(), "example.com", "changed", "A", rrset("changed"))
+	}()
+	go func() {
+		defer wg.Done()
+		// an update without records deletes the RRset, like a single update
+		cleared, clearErr = batcher.Update(context.Background(), "example.com", "cleared", "DS", dsc.RRSet{Records: []string{}, TTL: 3600})
+	}()
+	go func() {
+		defer wg.Done()
+		deleteErr = batcher.Delete(context.Background(), "example.com", "gone", "A")
+	}()
+	go func() {
+		defer wg.Done()
+		_, lostErr = batcher.Update(context.Background(), "example.com", "lost", "A", rrset("lost"))
+	}()
+	go func() {
+		defer wg.Done()
+		_, lostCreateErr = batcher.Create(context.Background(), dsc.RRSet{Domain: "example.com", SubName: "lost", Type: "AAAA", Records: []string{"::1"}, TTL: 3600})
+	}()
+	wg.Wait()
+
+	sort.Strings(requests)
+	if !reflect.DeepEqual(requests, []string{"PATCH /domains/example.com/rrsets/", "POST /domains/example.com/rrsets/"}) {
+		t.Fatalf("expected a bulk POST for the creates and a bulk PATCH for the rest, got %v", requests)
+	}
+	if createErr != nil || created == nil || created.SubName != "new" {
+		t.Errorf("create: got %v, %v", created, createErr)
+	}
+	if updateErr != nil || updated == nil || updated.SubName != "changed" {
+		t.Errorf("update: got %v, %v", updated, updateErr)
+	}
+	if clearErr != nil || cleared != nil {
+		t.Errorf("update without records: got %v, %v", cleared, clearErr)
+	}
+	if deleteErr != nil {
+		t.Errorf("delete: unexpected error %v", deleteErr)
+	}
+	if lostErr == nil || lostCreateErr == nil {
+		t.Errorf("expected errors for RRsets missing from the response, got %v, %v", lostErr, lostCreateErr)
+	}
+}
+
+func TestRRSetBatcherCreateConflict(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			t.Errorf("creates must not be sent as %s, which overwrites existing RRsets", req.Method)
+		}
+		body, _ := io.ReadAll(req.Body)
+
+		var rrset dsc.RRSet
+		if err := json.Unmarshal(body, &rrset); err != nil {
+			// bulk request containing an existing RRset
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`[{}, {"non_field_errors": ["Another RRset with the same subdomain and type exists for this domain."]}]`))
+			return
+		}
+		if rrset.SubName == "existing" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"non_field_errors": ["Another RRset with the same subdomain and type exists for this domain."]}`))
+			return
+		}
+		rrset.Domain = "example.com"
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(rrset)
+	}))
+
+	for _, window := range []time.Duration{0, 50 * time.Millisecond} {
+		batcher := NewRRSetBatcher(c, window)
+
+		errs := make(map[string]error)
+		var mutex sync.Mutex
+		var wg sync.WaitGroup
+		for _, subName := range []string{"fresh", "existing"} {
+			wg.Add(1)
+			go func(subName string) {
+				defer wg.Done()
+				_, err := batcher.Create(context.Background(), dsc.RRSet{
+					Domain:  "example.com",
+					SubName: subName,
+					Type:    "A",
+					Records: []string{"127.0.0.1"},
+					TTL:     3600,
+				})
+				mutex.Lock()
+				errs[subName] = err
+				mutex.Unlock()
+			}(subName)
+		}
+		wg.Wait()
+
+		if errs["fresh"] != nil {
+			t.Errorf("window %s: unexpected error %v", window, errs["fresh"])
+		}
+		if errs["existing"] == nil {
+			t.Errorf("window %s: expected a conflict for the existing RRset", window)
+		}
+	}
+}
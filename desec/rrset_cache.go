@@ -7,9 +7,20 @@ import (
 	dsc "github.com/nrdcg/desec"
 )
 
+// DesecCache holds the RRsets of each domain, fetched once per domain. The
+// mutex only guards the map, fetches for different domains run in parallel
+// and concurrent reads of the same domain share one fetch.
 type DesecCache struct {
 	mutex sync.Mutex
-	data  map[string]map[string]dsc.RRSet
+	data  map[string]*cachedDomain
+}
+
+type cachedDomain struct {
+	// closed once the fetch is done
+	ready chan struct{}
+	// nil if the domain doesn't exist. never modified once ready.
+	rrsets map[string]dsc.RRSet
+	err    error
 }
 
 func NewDesecCache() DesecCache {
@@ -22,38 +33,146 @@ func (r *DesecCache) GetRRSetById(ctx context.Context, c *dsc.Client, id string)
 		return nil, err
 	}
 
-	r.mutex.Lock()
+	rrsets, err := r.getDomain(ctx, c, domainName)
+	if err != nil {
+		return nil, err
+	}
 
-	defer func() {
-		r.mutex.Unlock()
-	}()
+	result, ok := rrsets[id]
+	if ok {
+		return &result, nil
+	} else {
+		return nil, nil
+	}
+}
 
+// GetRRSetsByDomain returns all RRsets of a domain, sorted by id. The result
+// is nil if the domain doesn't exist, and empty if it exists without RRsets.
+func (r *DesecCache) GetRRSetsByDomain(ctx context.Context, c *dsc.Client, domainName string) ([]dsc.RRSet, error) {
+	rrsets, err := r.getDomain(ctx, c, domainName)
+	if err != nil || rrsets == nil {
+		return nil, err
+	}
+	return sortedRRSets(rrsets), nil
+}
+
+// getDomain returns the RRsets of a domain keyed by id, fetching them if no
+// other caller is already doing so. It returns nil if the domain doesn't exist.
+func (r *DesecCache) getDomain(ctx context.Context, c *dsc.Client, domainName string) (map[string]dsc.RRSet, error) {
+	r.mutex.Lock()
 	if r.data == nil {
-		r.data = make(map[string]map[string]dsc.RRSet)
-	}
-	if r.data[domainName] == nil {
-		recs, err := c.Records.GetAll(ctx, domainName, nil)
-		if err != nil {
-			if isNotFoundError(err) {
-				return nil, nil
-			}
-			return nil, err
+		r.data = make(map[string]*cachedDomain)
+	}
+	entry := r.data[domainName]
+	if entry == nil {
+		entry = &cachedDomain{ready: make(chan struct{})}
+		r.data[domainName] = entry
+		// the fetch is shared with later callers, so it must not be cancelled
+		// along with the context of the caller that happened to start it
+		go r.fetch(context.WithoutCancel(ctx), c, domainName, entry)
+	}
+	r.mutex.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.rrsets, entry.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *DesecCache) fetch(ctx context.Context, c *dsc.Client, domainName string, entry *cachedDomain) {
+	entry.rrsets, entry.err = fetchDomainRRSets(ctx, c, domainName)
+	if entry.err != nil {
+		// don't keep failures around, the next read tries again
+		r.mutex.Lock()
+		if r.data[domainName] == entry {
+			delete(r.data, domainName)
 		}
+		r.mutex.Unlock()
+	}
+	close(entry.ready)
+}
 
-		d := make(map[string]dsc.RRSet)
-		for _, rec := range recs {
-			id := idFromNames(rec.Domain, rec.SubName, rec.Type)
-			d[id] = rec
+func fetchDomainRRSets(ctx context.Context, c *dsc.Client, domainName string) (map[string]dsc.RRSet, error) {
+	recs, err := getAllRRSets(ctx, c, domainName, nil)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil
 		}
-		r.data[domainName] = d
+		return nil, err
 	}
 
-	result, ok := r.data[domainName][id]
-	if ok {
-		return &result, nil
-	} else {
-		return nil, nil
+	d := make(map[string]dsc.RRSet)
+	for _, rec := range recs {
+		id := idFromNames(rec.Domain, rec.SubName, rec.Type)
+		d[id] = rec
+	}
+	return d, nil
+}
+
+// Put writes an RRset returned by the API into the cache, so a domain that is
+// already cached doesn't need to be fetched again after a change.
+func (r *DesecCache) Put(rrset dsc.RRSet) {
+	id := idFromNames(rrset.Domain, rrset.SubName, rrset.Type)
+	r.modify(rrset.Domain, func(rrsets map[string]dsc.RRSet) {
+		rrsets[id] = rrset
+	})
+}
+
+// Remove drops an RRset that was deleted from the cache.
+func (r *DesecCache) Remove(id string) {
+	domainName, _, _, err := namesFromId(id)
+	if err != nil {
+		return
+	}
+	r.modify(domainName, func(rrsets map[string]dsc.RRSet) {
+		delete(rrsets, id)
+	})
+}
+
+// Invalidate drops the cached RRsets of one domain, for changes whose outcome
+// isn't known in detail.
+func (r *DesecCache) Invalidate(domainName string) {
+	r.mutex.Lock()
+	delete(r.data, domainName)
+	r.mutex.Unlock()
+}
+
+// modify applies a change to a copy of the cached RRsets of a domain. Domains
+// that aren't cached stay that way, they are fetched with the change on the
+// next read.
+func (r *DesecCache) modify(domainName string, change func(map[string]dsc.RRSet)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entry := r.data[domainName]
+	if entry == nil {
+		return
 	}
+
+	select {
+	case <-entry.ready:
+	default:
+		// a fetch is in flight and may or may not see the change
+		delete(r.data, domainName)
+		return
+	}
+
+	if entry.err != nil || entry.rrsets == nil {
+		delete(r.data, domainName)
+		return
+	}
+
+	rrsets := make(map[string]dsc.RRSet, len(entry.rrsets)+1)
+	for id, rrset := range entry.rrsets {
+		rrsets[id] = rrset
+	}
+	change(rrsets)
+
+	ready := make(chan struct{})
+	close(ready)
+	r.data[domainName] = &cachedDomain{ready: ready, rrsets: rrsets}
 }
 
 func (r *DesecCache) Clear() {
@@ -0,0 +1,193 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+func TestDesecCacheSharesFetches(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		<-release
+		json.NewEncoder(w).Encode([]dsc.RRSet{
+			{Domain: "example.com", SubName: "www", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600},
+		})
+	}))
+
+	cache := NewDesecCache()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := cache.GetRRSetById(context.Background(), c, "example.com/www/A")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if r == nil {
+				t.Error("RRset not found")
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected one fetch, got %d", n)
+	}
+}
+
+func TestDesecCacheFetchOutlivesCancelledCaller(t *testing.T) {
+	release := make(chan struct{})
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		json.NewEncoder(w).Encode([]dsc.RRSet{
+			{Domain: "example.com", SubName: "www", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600},
+		})
+	}))
+
+	cache := NewDesecCache()
+
+	// the first caller starts the fetch and gives up before it is done
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := cache.GetRRSetById(ctx, c, "example.com/www/A")
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan error)
+	go func() {
+		r, err := cache.GetRRSetById(context.Background(), c, "example.com/www/A")
+		if err == nil && r == nil {
+			err = errors.New("RRset not found")
+		}
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the first caller to be cancelled, got %v", err)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller failed: %v", err)
+	}
+}
+
+func TestDesecCacheFetchesDomainsInParallel(t *testing.T) {
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	both := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(both)
+	}()
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		arrived.Done()
+		select {
+		case <-both:
+		case <-time.After(5 * time.Second):
+			// the other domain never got fetched while this one was pending
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		domainName := strings.Split(req.URL.Path, "/")[2]
+		json.NewEncoder(w).Encode([]dsc.RRSet{
+			{Domain: domainName, SubName: "", Type: "NS", Records: []string{"ns1.desec.io."}, TTL: 3600},
+		})
+	}))
+
+	cache := NewDesecCache()
+
+	var wg sync.WaitGroup
+	for _, domainName := range []string{"one.example", "two.example"} {
+		wg.Add(1)
+		go func(domainName string) {
+			defer wg.Done()
+			rrsets, err := cache.GetRRSetsByDomain(context.Background(), c, domainName)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(rrsets) != 1 {
+				t.Errorf("expected one RRset for %s, got %d", domainName, len(rrsets))
+			}
+		}(domainName)
+	}
+	wg.Wait()
+}
+
+func TestDesecCacheWriteThrough(t *testing.T) {
+	var fetches atomic.Int32
+
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode([]dsc.RRSet{
+			{Domain: "example.com", SubName: "www", Type: "A", Records: []string{"127.0.0.1"}, TTL: 3600},
+		})
+	}))
+
+	cache := NewDesecCache()
+	ctx := context.Background()
+
+	// writes to a domain that isn't cached yet are left to the next fetch
+	cache.Put(dsc.RRSet{Domain: "example.com", SubName: "mail", Type: "A", Records: []string{"127.0.0.2"}, TTL: 3600})
+	r, err := cache.GetRRSetById(ctx, c, "example.com/mail/A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != nil {
+		t.Fatalf("expected RRset of uncached domain to come from the API, got %v", r)
+	}
+
+	cache.Put(dsc.RRSet{Domain: "example.com", SubName: "mail", Type: "A", Records: []string{"127.0.0.2"}, TTL: 3600})
+	cache.Remove("example.com/www/A")
+
+	r, err = cache.GetRRSetById(ctx, c, "example.com/mail/A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r == nil || r.Records[0] != "127.0.0.2" {
+		t.Fatalf("expected written RRset, got %v", r)
+	}
+	r, err = cache.GetRRSetById(ctx, c, "example.com/www/A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != nil {
+		t.Fatalf("expected removed RRset to be gone, got %v", r)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected one fetch, got %d", n)
+	}
+
+	cache.Invalidate("example.com")
+	if _, err := cache.GetRRSetById(ctx, c, "example.com/www/A"); err != nil {
+		t.Fatal(err)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Fatalf("expected a fetch after invalidation, got %d", n)
+	}
+}
@@ -0,0 +1,204 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// tokenLifetimes holds the token fields that the client doesn't support. The
+// API represents them as Django durations, nil for no limit.
+type tokenLifetimes struct {
+	MaxAge          *string `json:"max_age"`
+	MaxUnusedPeriod *string `json:"max_unused_period"`
+}
+
+// getTokenWithLifetimes reads a token including its lifetimes. It returns nil
+// if the token doesn't exist.
+func getTokenWithLifetimes(ctx context.Context, conf *DesecConfig, id string) (*dsc.Token, *tokenLifetimes, error) {
+	body, err := conf.apiRequest(ctx, http.MethodGet, []string{"auth", "tokens", id}, nil)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	return unmarshalTokenWithLifetimes(body)
+}
+
+// updateTokenLifetimes sets the lifetimes of a token from the schema.
+func updateTokenLifetimes(ctx context.Context, conf *DesecConfig, id string, d *schema.ResourceData) (*dsc.Token, *tokenLifetimes, error) {
+	changes := make(map[string]interface{})
+	for _, k := range []string{"max_age", "max_unused_period"} {
+		changes[k] = nil
+		if v := d.Get(k).(string); v != "" {
+			duration, err := parseDuration(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", k, err)
+			}
+			changes[k] = formatDjangoDuration(duration)
+		}
+	}
+
+	body, err := conf.apiRequest(ctx, http.MethodPatch, []string{"auth", "tokens", id}, changes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return unmarshalTokenWithLifetimes(body)
+}
+
+func unmarshalTokenWithLifetimes(body []byte) (*dsc.Token, *tokenLifetimes, error) {
+	var token dsc.Token
+	var lifetimes tokenLifetimes
+	for _, v := range []interface{}{&token, &lifetimes} {
+		if err := json.Unmarshal(body, v); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal response body: %w", err)
+		}
+	}
+	return &token, &lifetimes, nil
+}
+
+func tokenLifetimesIntoSchema(l *tokenLifetimes, d *schema.ResourceData) error {
+	for k, v := range map[string]*string{"max_age": l.MaxAge, "max_unused_period": l.MaxUnusedPeriod} {
+		if v == nil {
+			d.Set(k, "")
+			continue
+		}
+		duration, err := parseDjangoDuration(*v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		d.Set(k, duration.String())
+	}
+	return nil
+}
+
+// parseDjangoDuration parses a duration in the format Django serializes them,
+// "[DD ][[HH:]MM:]ss[.uuuuuu]", such as "365 00:00:00".
+func parseDjangoDuration(s string) (time.Duration, error) {
+	var result time.Duration
+
+	rest := strings.TrimSpace(s)
+	if days, clock, ok := strings.Cut(rest, " "); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		result += time.Duration(n) * 24 * time.Hour
+		rest = strings.TrimSpace(clock)
+	}
+
+	parts := strings.Split(rest, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	seconds, micros, hasMicros := strings.Cut(parts[len(parts)-1], ".")
+	n, err := strconv.Atoi(seconds)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	result += time.Duration(n) * time.Second
+	if hasMicros {
+		if len(micros) == 0 || len(micros) > 6 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		n, err := strconv.Atoi(micros + strings.Repeat("0", 6-len(micros)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		result += time.Duration(n) * time.Microsecond
+	}
+
+	units := []time.Duration{time.Minute, time.Hour}
+	for i, p := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		result += time.Duration(n) * units[len(parts)-2-i]
+	}
+	return result, nil
+}
+
+// formatDjangoDuration formats a duration the way Django does.
+func formatDjangoDuration(d time.Duration) string {
+	d = d.Truncate(time.Microsecond)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+
+	result := fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+	if days > 0 {
+		result = fmt.Sprintf("%d %s", days, result)
+	}
+	if d > 0 {
+		result += fmt.Sprintf(".%06d", d/time.Microsecond)
+	}
+	return result
+}
+
+// parseDuration parses a Go duration, which may start with a number of days,
+// such as "90d" or "1d12h".
+func parseDuration(s string) (time.Duration, error) {
+	days, rest, ok := strings.Cut(s, "d")
+	if !ok {
+		return time.ParseDuration(s)
+	}
+
+	n, err := strconv.Atoi(days)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	result := time.Duration(n) * 24 * time.Hour
+	if rest != "" {
+		d, err := time.ParseDuration(rest)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		result += d
+	}
+	return result, nil
+}
+
+// durationsEqual suppresses diffs between different ways to write the same
+// duration, such as 90d, 2160h and 2160h0m0s.
+func durationsEqual(k, old, new string, d *schema.ResourceData) bool {
+	o, err := parseDuration(old)
+	if err != nil {
+		return false
+	}
+	n, err := parseDuration(new)
+	if err != nil {
+		return false
+	}
+	return o == n
+}
+
+// canonicalCIDR returns a subnet in the form the API returns it, so that
+// different ways to write the same subnet hash the same.
+func canonicalCIDR(s string) string {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return s
+	}
+	return network.String()
+}
+
+func allowedSubnetHash(v interface{}) int {
+	return schema.HashString(canonicalCIDR(v.(string)))
+}
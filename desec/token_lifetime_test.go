@@ -0,0 +1,88 @@
+package desec
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDjangoDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"365 00:00:00":    365 * 24 * time.Hour,
+		"1 02:03:04":      26*time.Hour + 3*time.Minute + 4*time.Second,
+		"00:30:00":        30 * time.Minute,
+		"00:00:01.500000": 1500 * time.Millisecond,
+	}
+	for s, d := range cases {
+		parsed, err := parseDjangoDuration(s)
+		if err != nil {
+			t.Fatalf("%s: %s", s, err)
+		}
+		if parsed != d {
+			t.Errorf("%s: expected %s, got %s", s, d, parsed)
+		}
+		if formatted := formatDjangoDuration(d); formatted != s {
+			t.Errorf("%s: expected %q, got %q", d, s, formatted)
+		}
+	}
+
+	// forms Django accepts, but doesn't produce
+	for s, d := range map[string]time.Duration{"90": 90 * time.Second, "5:00": 5 * time.Minute, "00:00:00.5": 500 * time.Millisecond} {
+		parsed, err := parseDjangoDuration(s)
+		if err != nil || parsed != d {
+			t.Errorf("%s: expected %s, got %s, %v", s, d, parsed, err)
+		}
+	}
+
+	for _, s := range []string{"", "x 00:00:00", "1:2:3:4", "00:00:00.1234567"} {
+		if _, err := parseDjangoDuration(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90d":    90 * 24 * time.Hour,
+		"2160h":  90 * 24 * time.Hour,
+		"1d12h":  36 * time.Hour,
+		"0d30m":  30 * time.Minute,
+		"1h30m":  90 * time.Minute,
+		"365d0s": 365 * 24 * time.Hour,
+	}
+	for s, d := range cases {
+		parsed, err := parseDuration(s)
+		if err != nil {
+			t.Fatalf("%s: %s", s, err)
+		}
+		if parsed != d {
+			t.Errorf("%s: expected %s, got %s", s, d, parsed)
+		}
+	}
+
+	for _, s := range []string{"", "d", "90", "1.5d", "-1d", "1d-1h", "1dx", "90days"} {
+		if _, err := parseDuration(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+
+	if !durationsEqual("max_age", "2160h0m0s", "90d", nil) {
+		t.Errorf("expected 90d and 2160h0m0s to be equal")
+	}
+}
+
+func TestAllowedSubnetHash(t *testing.T) {
+	same := [][2]string{
+		{"2001:DB8::/32", "2001:db8::/32"},
+		{"2001:db8:0:0::/64", "2001:db8::/64"},
+		{"192.0.2.1/24", "192.0.2.0/24"},
+		{"0.0.0.0/0", "0.0.0.0/0"},
+	}
+	for _, pair := range same {
+		if allowedSubnetHash(pair[0]) != allowedSubnetHash(pair[1]) {
+			t.Errorf("expected %s and %s to hash the same", pair[0], pair[1])
+		}
+	}
+	if allowedSubnetHash("0.0.0.0/0") == allowedSubnetHash("::/0") {
+		t.Error("expected IPv4 and IPv6 subnets to differ")
+	}
+}
@@ -0,0 +1,160 @@
+package desec
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// tokenRotations pairs a rotated token with the token it replaces, to carry
+// the policies of the replaced token over. Terraform plans a replacement as an
+// unrelated creation and deletion, so the two are matched by name within one
+// run. The deletion knows the exact token it removes, and hands its policies
+// to the token of the same name created earlier in this run, as it happens
+// with create_before_destroy. Without it, they are kept for the token created
+// afterwards.
+type tokenRotations struct {
+	mutex sync.Mutex
+	// IDs of rotated tokens created in this run, by name
+	successors map[string][]string
+	// rotated tokens deleted in this run, by name
+	predecessors map[string]*rotatedToken
+	// policies copied onto a token in this run, by token ID, see Adopt
+	copies map[string][]dsc.TokenPolicy
+}
+
+// rotatedToken is a deleted token whose policies await its successor.
+type rotatedToken struct {
+	id       string
+	policies []dsc.TokenPolicy
+	// the token was already gone, so its policies are unknown
+	missing bool
+}
+
+func newTokenRotations() tokenRotations {
+	return tokenRotations{
+		successors:   make(map[string][]string),
+		predecessors: make(map[string]*rotatedToken),
+		copies:       make(map[string][]dsc.TokenPolicy),
+	}
+}
+
+// CheckPredecessor fails if a token of the given name was deleted in this run
+// that no longer existed, since its policies can't be carried over then. It is
+// called before creating a rotated token, to not create it in vain.
+func (r *tokenRotations) CheckPredecessor(name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if old := r.predecessors[name]; old != nil && old.missing {
+		return fmt.Errorf("token %s replaced by this token no longer exists, so its policies can't be copied", old.id)
+	}
+	return nil
+}
+
+// Created records a new rotated token, and copies the policies of the token of
+// the same name deleted earlier in this run, if any. A first creation has no
+// predecessor and starts out without policies. A predecessor that was deleted
+// first means the resource lacks create_before_destroy, which is warned about,
+// since the provider can't see the lifecycle of a resource to enforce it.
+func (r *tokenRotations) Created(ctx context.Context, c *dsc.Client, token *dsc.Token) diag.Diagnostics {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	old := r.predecessors[token.Name]
+	if old == nil {
+		r.successors[token.Name] = append(r.successors[token.Name], token.ID)
+		return nil
+	}
+
+	delete(r.predecessors, token.Name)
+	if old.missing {
+		return diag.Errorf("token %s replaced by this token no longer exists, so its policies can't be copied", old.id)
+	}
+	if err := r.copyPolicies(ctx, c, old.id, token.ID, old.policies); err != nil {
+		return diag.FromErr(err)
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Rotated token without create_before_destroy",
+		Detail: fmt.Sprintf("Token %s was deleted before its replacement %s was created, so there was no valid token in between. "+
+			"Set create_before_destroy in the lifecycle of tokens with rotate_after or keepers.", old.id, token.ID),
+	}}
+}
+
+// Deleting is called before a rotated token is deleted. It copies the token's
+// policies to the token of the same name created earlier in this run, or keeps
+// them for one created later in this run.
+func (r *tokenRotations) Deleting(ctx context.Context, c *dsc.Client, id, name string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	policies, err := c.TokenPolicies.GetAll(ctx, id)
+	missing := isNotFoundError(err)
+	if err != nil && !missing {
+		return err
+	}
+
+	successors := r.successors[name]
+	switch {
+	case len(successors) > 1:
+		return fmt.Errorf("%d tokens named %q were created in this run, so the one replacing token %s is unknown. Give rotated tokens unique names", len(successors), name, id)
+	case len(successors) == 1:
+		if missing {
+			return fmt.Errorf("token %s replaced by token %s no longer exists, so its policies can't be copied", id, successors[0])
+		}
+		delete(r.successors, name)
+		return r.copyPolicies(ctx, c, id, successors[0], policies)
+	}
+
+	r.predecessors[name] = &rotatedToken{id: id, policies: policies, missing: missing}
+	return nil
+}
+
+// copyPolicies creates the given policies of one token on another one, except
+// for those the other token already has.
+func (r *tokenRotations) copyPolicies(ctx context.Context, c *dsc.Client, from, to string, policies []dsc.TokenPolicy) error {
+	existing, err := c.TokenPolicies.GetAll(ctx, to)
+	if err != nil {
+		return err
+	}
+
+	// the default policy has to exist before any other
+	sort.SliceStable(policies, func(i, j int) bool {
+		return isDefaultTokenPolicy(policies[i]) && !isDefaultTokenPolicy(policies[j])
+	})
+	for _, p := range policies {
+		if findTokenPolicy(existing, p) != nil {
+			continue
+		}
+		p.ID = ""
+		created, err := c.TokenPolicies.Create(ctx, to, p)
+		if err != nil {
+			return fmt.Errorf("failed to copy policies of token %s to token %s: %w", from, to, err)
+		}
+		r.copies[to] = append(r.copies[to], *created)
+	}
+	return nil
+}
+
+// Adopt returns the policy of the token for the same domain, subname and type
+// as the wanted one, if it was copied in this run. Each copy is adopted once.
+func (r *tokenRotations) Adopt(tokenID string, want dsc.TokenPolicy) *dsc.TokenPolicy {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	copies := r.copies[tokenID]
+	found := findTokenPolicy(copies, want)
+	if found == nil {
+		return nil
+	}
+	adopted := *found
+	r.copies[tokenID] = slices.DeleteFunc(copies, func(p dsc.TokenPolicy) bool { return p.ID == adopted.ID })
+	return &adopted
+}
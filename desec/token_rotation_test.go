@@ -0,0 +1,261 @@
+package desec
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// tokenPolicyServer keeps the policies of some tokens, and like the API
+// refuses other policies before the default one.
+type tokenPolicyServer struct {
+	mutex    sync.Mutex
+	policies map[string][]dsc.TokenPolicy
+	nextID   int
+}
+
+func (s *tokenPolicyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	path, _ := strings.CutPrefix(req.URL.Path, "/auth/tokens/")
+	tokenID, policyID, ok := strings.Cut(path, "/policies/rrsets/")
+	policyID = strings.TrimSuffix(policyID, "/")
+	policies, exists := s.policies[tokenID]
+	if !ok || !exists {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPatch:
+		var p dsc.TokenPolicy
+		json.NewDecoder(req.Body).Decode(&p)
+		for i := range policies {
+			if policies[i].ID == policyID {
+				p.ID = policyID
+				policies[i] = p
+				json.NewEncoder(w).Encode(p)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(policies)
+	case http.MethodPost:
+		var p dsc.TokenPolicy
+		json.NewDecoder(req.Body).Decode(&p)
+		if findTokenPolicy(policies, dsc.TokenPolicy{}) == nil && !isDefaultTokenPolicy(p) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"detail": "Policy precedence: The first policy must be the default policy."}`))
+			return
+		}
+		if findTokenPolicy(policies, p) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"non_field_errors": ["Policy already exists."]}`))
+			return
+		}
+		s.nextID++
+		p.ID = strings.Repeat("p", s.nextID)
+		s.policies[tokenID] = append(policies, p)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTokenPolicyServer() *tokenPolicyServer {
+	domain := "example.com"
+	return &tokenPolicyServer{policies: map[string][]dsc.TokenPolicy{
+		"old": {
+			{ID: "domain", Domain: &domain, WritePermission: true},
+			{ID: "default"},
+		},
+		"other": {
+			{ID: "other-default"},
+		},
+		"new": {},
+	}}
+}
+
+func TestTokenRotationCreateBeforeDestroy(t *testing.T) {
+	server := newTokenPolicyServer()
+	// the default policy of the new token is managed, and created first
+	server.policies["new"] = []dsc.TokenPolicy{{ID: "managed-default"}}
+	c := newTestClient(t, server)
+	rotations := newTokenRotations()
+	ctx := context.Background()
+
+	if err := rotations.CheckPredecessor("ci"); err != nil {
+		t.Fatal(err)
+	}
+	if diags := rotations.Created(ctx, c, &dsc.Token{ID: "new", Name: "ci"}); len(diags) != 0 {
+		t.Fatal(diags)
+	}
+	if len(server.policies["new"]) != 1 {
+		t.Fatalf("expected no policies to be copied before the old token is deleted, got %v", server.policies["new"])
+	}
+
+	if err := rotations.Deleting(ctx, c, "old", "ci"); err != nil {
+		t.Fatal(err)
+	}
+	copied := server.policies["new"]
+	if len(copied) != 2 || copied[0].ID != "managed-default" || copied[1].Domain == nil || !copied[1].WritePermission {
+		t.Fatalf("expected the domain policy to be copied, got %v", copied)
+	}
+	if len(server.policies["other"]) != 1 {
+		t.Errorf("token of another name was changed: %v", server.policies["other"])
+	}
+}
+
+func TestTokenRotationDestroyBeforeCreate(t *testing.T) {
+	server := newTokenPolicyServer()
+	c := newTestClient(t, server)
+	rotations := newTokenRotations()
+	ctx := context.Background()
+
+	if err := rotations.Deleting(ctx, c, "old", "ci"); err != nil {
+		t.Fatal(err)
+	}
+	delete(server.policies, "old")
+
+	if err := rotations.CheckPredecessor("ci"); err != nil {
+		t.Fatal(err)
+	}
+	diags := rotations.Created(ctx, c, &dsc.Token{ID: "new", Name: "ci"})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Errorf("expected a warning about the missing create_before_destroy, got %v", diags)
+	}
+	copied := server.policies["new"]
+	if len(copied) != 2 || !isDefaultTokenPolicy(copied[0]) || copied[1].Domain == nil {
+		t.Fatalf("expected the default policy to be copied first, got %v", copied)
+	}
+}
+
+func TestTokenRotationFirstCreate(t *testing.T) {
+	server := newTokenPolicyServer()
+	c := newTestClient(t, server)
+	rotations := newTokenRotations()
+	ctx := context.Background()
+
+	// an existing token of the same name isn't taken for the predecessor
+	if diags := rotations.Created(ctx, c, &dsc.Token{ID: "new", Name: "ci"}); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if len(server.policies["new"]) != 0 {
+		t.Fatalf("expected no policies, got %v", server.policies["new"])
+	}
+
+	// neither is the one deleted later, unless it is rotated too
+	if err := rotations.Deleting(ctx, c, "other", "something else"); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.policies["new"]) != 0 {
+		t.Fatalf("expected no policies, got %v", server.policies["new"])
+	}
+}
+
+func TestTokenRotationMissingPredecessor(t *testing.T) {
+	server := newTokenPolicyServer()
+	c := newTestClient(t, server)
+	ctx := context.Background()
+
+	// destroy before create: fails before creating the new token
+	rotations := newTokenRotations()
+	if err := rotations.Deleting(ctx, c, "gone", "ci"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rotations.CheckPredecessor("ci"); err == nil {
+		t.Errorf("expected an error for a missing predecessor")
+	}
+	if err := rotations.CheckPredecessor("something else"); err != nil {
+		t.Errorf("unexpected error for another name: %s", err)
+	}
+
+	// create before destroy: fails when deleting the missing token
+	rotations = newTokenRotations()
+	if diags := rotations.Created(ctx, c, &dsc.Token{ID: "new", Name: "ci"}); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if err := rotations.Deleting(ctx, c, "gone", "ci"); err == nil {
+		t.Errorf("expected an error for a missing predecessor")
+	}
+}
+
+func TestTokenRotationAmbiguousSuccessor(t *testing.T) {
+	server := newTokenPolicyServer()
+	server.policies["new2"] = []dsc.TokenPolicy{}
+	c := newTestClient(t, server)
+	rotations := newTokenRotations()
+	ctx := context.Background()
+
+	for _, id := range []string{"new", "new2"} {
+		if diags := rotations.Created(ctx, c, &dsc.Token{ID: id, Name: "ci"}); diags.HasError() {
+			t.Fatal(diags)
+		}
+	}
+	if err := rotations.Deleting(ctx, c, "old", "ci"); err == nil {
+		t.Errorf("expected an error for two successors")
+	}
+}
+
+func TestTokenPolicyCreateAdoptsOnlyCopies(t *testing.T) {
+	server := newTokenPolicyServer()
+	c := newTestClient(t, server)
+	rotations := newTokenRotations()
+	conf := &DesecConfig{client: c, rotations: &rotations}
+	ctx := context.Background()
+
+	if err := rotations.Deleting(ctx, c, "old", "ci"); err != nil {
+		t.Fatal(err)
+	}
+	if diags := rotations.Created(ctx, c, &dsc.Token{ID: "new", Name: "ci"}); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	createPolicy := func(tokenID, domain string) (*schema.ResourceData, bool) {
+		d := schema.TestResourceDataRaw(t, resourceTokenPolicy().Schema, map[string]interface{}{
+			"token_id":   tokenID,
+			"domain":     domain,
+			"perm_write": false,
+		})
+		return d, !resourceTokenPolicyCreate(ctx, d, conf).HasError()
+	}
+
+	// the copied domain policy is adopted, and updated to the configuration
+	d, ok := createPolicy("new", "example.com")
+	if !ok {
+		t.Fatal("expected the copied policy to be adopted")
+	}
+	copied := server.policies["new"]
+	if len(copied) != 2 || d.Id() != copied[1].ID || copied[1].WritePermission {
+		t.Fatalf("expected the copied policy %v to be updated, got id %q", copied, d.Id())
+	}
+
+	// but only once
+	if _, ok := createPolicy("new", "example.com"); ok {
+		t.Errorf("expected a second policy for the same domain to fail")
+	}
+
+	// policies that weren't copied are never adopted
+	if _, ok := createPolicy("other", ""); ok {
+		t.Errorf("expected an existing default policy to fail")
+	}
+	if len(server.policies["other"]) != 1 {
+		t.Errorf("existing policy was changed: %v", server.policies["other"])
+	}
+}
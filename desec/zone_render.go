@@ -0,0 +1,156 @@
+package desec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// The renderers below take RRsets sorted by id, with records normalized by
+// normalizeRecordSet, so the output only changes when the zone does.
+
+// renderZoneBIND renders RRsets as zone file text.
+func renderZoneBIND(domainName string, rrsets []dsc.RRSet) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s.\n", domainName)
+	for _, r := range rrsets {
+		owner := r.SubName
+		if owner == "" {
+			owner = "@"
+		}
+		for _, rec := range r.Records {
+			if r.Type == "TXT" || r.Type == "SPF" {
+				rec = quoteTXT(rec)
+			}
+			fmt.Fprintf(&b, "%s\t%d\tIN\t%s\t%s\n", owner, r.TTL, r.Type, rec)
+		}
+	}
+	return b.String()
+}
+
+// quoteTXT quotes normalized TXT content, split into strings of at most 255
+// characters as the zone file format requires.
+func quoteTXT(s string) string {
+	var chunks []string
+	for len(s) > 255 {
+		n := 255
+		// don't split escape sequences
+		for i := 0; i < n; i++ {
+			if s[i] == '\\' {
+				if i+1 >= n {
+					n = i
+					break
+				}
+				i++
+			}
+		}
+		chunks = append(chunks, s[:n])
+		s = s[n:]
+	}
+	chunks = append(chunks, s)
+	return "\"" + strings.Join(chunks, "\" \"") + "\""
+}
+
+type renderedZone struct {
+	Domain string          `json:"domain"`
+	RRSets []renderedRRSet `json:"rrsets"`
+}
+
+type renderedRRSet struct {
+	SubName string   `json:"subname"`
+	Type    string   `json:"type"`
+	TTL     int      `json:"ttl"`
+	Records []string `json:"records"`
+}
+
+// renderZoneJSON renders RRsets as indented JSON.
+func renderZoneJSON(domainName string, rrsets []dsc.RRSet) (string, error) {
+	zone := renderedZone{Domain: domainName, RRSets: []renderedRRSet{}}
+	for _, r := range rrsets {
+		zone.RRSets = append(zone.RRSets, renderedRRSet{r.SubName, r.Type, r.TTL, r.Records})
+	}
+	result, err := json.MarshalIndent(zone, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(result) + "\n", nil
+}
+
+// Field names of record types that octoDNS represents as structured values.
+var octodnsValueFields = map[string][]string{
+	"CAA":   {"flags", "tag", "value"},
+	"MX":    {"preference", "exchange"},
+	"SRV":   {"priority", "weight", "port", "target"},
+	"SSHFP": {"algorithm", "fingerprint_type", "fingerprint"},
+	"TLSA":  {"certificate_usage", "selector", "matching_type", "certificate_association_data"},
+}
+
+// Record types that octoDNS expects a single value for.
+var octodnsSingleValueTypes = map[string]bool{
+	"CNAME": true,
+	"DNAME": true,
+	"PTR":   true,
+}
+
+// renderZoneOctoDNS renders RRsets in the YAML format of octoDNS' YamlProvider.
+// All strings are written as double quoted scalars, which are valid YAML.
+func renderZoneOctoDNS(rrsets []dsc.RRSet) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+
+	lastSubName := ""
+	for i, r := range rrsets {
+		if i == 0 || r.SubName != lastSubName {
+			fmt.Fprintf(&b, "%s:\n", yamlString(r.SubName))
+			lastSubName = r.SubName
+		}
+		fmt.Fprintf(&b, "- type: %s\n", yamlString(r.Type))
+		fmt.Fprintf(&b, "  ttl: %d\n", r.TTL)
+
+		if octodnsSingleValueTypes[r.Type] && len(r.Records) == 1 {
+			fmt.Fprintf(&b, "  value: %s\n", yamlString(r.Records[0]))
+			continue
+		}
+
+		b.WriteString("  values:\n")
+		fields, structured := octodnsValueFields[r.Type]
+		for _, rec := range r.Records {
+			parts := strings.Fields(rec)
+			if !structured || len(parts) != len(fields) {
+				if r.Type == "TXT" || r.Type == "SPF" {
+					rec = strings.ReplaceAll(rec, ";", "\\;")
+				}
+				fmt.Fprintf(&b, "  - %s\n", yamlString(rec))
+				continue
+			}
+			for j, f := range fields {
+				prefix := "    "
+				if j == 0 {
+					prefix = "  - "
+				}
+				fmt.Fprintf(&b, "%s%s: %s\n", prefix, f, yamlScalar(strings.Trim(parts[j], "\"")))
+			}
+		}
+	}
+	return b.String()
+}
+
+// yamlString quotes a string the JSON way, which YAML accepts as well.
+func yamlString(s string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// yamlScalar writes numbers as they are and everything else as a string.
+func yamlScalar(s string) string {
+	if _, err := strconv.Atoi(s); err == nil {
+		return s
+	}
+	return yamlString(s)
+}
@@ -0,0 +1,70 @@
+package desec
+
+import (
+	"strings"
+	"testing"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+var testRenderRRSets = []dsc.RRSet{
+	{Domain: "example.com", SubName: "", Type: "MX", TTL: 3600, Records: []string{"10 mail.example.com."}},
+	{Domain: "example.com", SubName: "", Type: "TXT", TTL: 3600, Records: []string{"v=spf1 -all; x"}},
+	{Domain: "example.com", SubName: "www", Type: "CNAME", TTL: 300, Records: []string{"example.com."}},
+}
+
+func TestRenderZoneBIND(t *testing.T) {
+	expected := "$ORIGIN example.com.\n" +
+		"@\t3600\tIN\tMX\t10 mail.example.com.\n" +
+		"@\t3600\tIN\tTXT\t\"v=spf1 -all; x\"\n" +
+		"www\t300\tIN\tCNAME\texample.com.\n"
+	if result := renderZoneBIND("example.com", testRenderRRSets); result != expected {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+
+	// the rendered zone file parses back into the same RRsets
+	parsed, err := parseZonefile(renderZoneBIND("example.com", testRenderRRSets), "example.com", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range testRenderRRSets {
+		p := parsed[idFromNames(r.Domain, r.SubName, r.Type)]
+		if p.TTL != r.TTL || strings.Join(p.Records, ",") != strings.Join(r.Records, ",") {
+			t.Errorf("expected %v, got %v", r, p)
+		}
+	}
+}
+
+func TestQuoteTXT(t *testing.T) {
+	long := strings.Repeat("x", 254) + "\\\"" + strings.Repeat("y", 10)
+	quoted := quoteTXT(long)
+	expected := "\"" + strings.Repeat("x", 254) + "\" \"\\\"" + strings.Repeat("y", 10) + "\""
+	if quoted != expected {
+		t.Fatalf("unexpected result %s", quoted)
+	}
+	if normalizeLongRecord(quoted) != long {
+		t.Fatalf("quoting doesn't normalize back")
+	}
+}
+
+func TestRenderZoneOctoDNS(t *testing.T) {
+	expected := `---
+"":
+- type: "MX"
+  ttl: 3600
+  values:
+  - preference: 10
+    exchange: "mail.example.com."
+- type: "TXT"
+  ttl: 3600
+  values:
+  - "v=spf1 -all\\; x"
+"www":
+- type: "CNAME"
+  ttl: 300
+  value: "example.com."
+`
+	if result := renderZoneOctoDNS(testRenderRRSets); result != expected {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+}
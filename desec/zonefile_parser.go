@@ -0,0 +1,302 @@
+package desec
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	dsc "github.com/nrdcg/desec"
+)
+
+// Record types accepted by the RRSet API.
+// https://desec.readthedocs.io/en/latest/dns/rrsets.html#supported-types
+var supportedRecordTypes = map[string]bool{
+	"A": true, "AAAA": true, "AFSDB": true, "APL": true, "CAA": true, "CDNSKEY": true,
+	"CDS": true, "CERT": true, "CNAME": true, "DHCID": true, "DNAME": true, "DNSKEY": true,
+	"DLV": true, "DS": true, "EUI48": true, "EUI64": true, "HINFO": true, "HTTPS": true,
+	"KX": true, "L32": true, "L64": true, "LOC": true, "LP": true, "MX": true, "NAPTR": true,
+	"NID": true, "NS": true, "OPENPGPKEY": true, "PTR": true, "RP": true, "SMIMEA": true,
+	"SPF": true, "SRV": true, "SSHFP": true, "SVCB": true, "TLSA": true, "TXT": true, "URI": true,
+}
+
+// For types with domain names in their content, the index of the field that
+// holds the name. Relative names there are qualified with the origin.
+var recordNameFields = map[string]int{
+	"CNAME": 0,
+	"DNAME": 0,
+	"NS":    0,
+	"PTR":   0,
+	"MX":    1,
+	"KX":    1,
+	"AFSDB": 1,
+	"SRV":   3,
+}
+
+// zonefileEntry is one logical line of a zone file, with parentheses joined.
+type zonefileEntry struct {
+	line int
+	// the entry starts with blanks, so it has no owner name
+	continued bool
+	tokens    []string
+}
+
+// parseZonefile parses zone file text in BIND format into the RRsets of a
+// domain, keyed by RRset id. SOA records are skipped, since deSEC manages
+// them. Other types the API doesn't accept are an error, as are records
+// outside of the domain.
+func parseZonefile(text, domainName string, defaultTTL int) (map[string]dsc.RRSet, error) {
+	entries, err := tokenizeZonefile(text)
+	if err != nil {
+		return nil, err
+	}
+
+	domainName = strings.ToLower(strings.TrimSuffix(domainName, "."))
+	origin := domainName + "."
+	owner := ""
+	// without $TTL, records default to the last explicit TTL (RFC 1035)
+	directiveTTL := -1
+	lastTTL := -1
+
+	result := make(map[string]dsc.RRSet)
+	for _, e := range entries {
+		tokens := e.tokens
+
+		if strings.HasPrefix(tokens[0], "$") && !e.continued {
+			switch strings.ToUpper(tokens[0]) {
+			case "$ORIGIN":
+				if len(tokens) != 2 {
+					return nil, fmt.Errorf("line %d: $ORIGIN needs exactly one name", e.line)
+				}
+				origin = qualifyName(tokens[1], origin)
+			case "$TTL":
+				if len(tokens) != 2 {
+					return nil, fmt.Errorf("line %d: $TTL needs exactly one value", e.line)
+				}
+				directiveTTL, err = parseZonefileTTL(tokens[1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", e.line, err)
+				}
+			default:
+				return nil, fmt.Errorf("line %d: unsupported directive %s", e.line, tokens[0])
+			}
+			continue
+		}
+
+		if !e.continued {
+			owner = qualifyName(tokens[0], origin)
+			tokens = tokens[1:]
+		} else if owner == "" {
+			return nil, fmt.Errorf("line %d: record without owner name", e.line)
+		}
+
+		ttl := -1
+		for len(tokens) > 0 {
+			if strings.EqualFold(tokens[0], "IN") {
+				tokens = tokens[1:]
+			} else if c := strings.ToUpper(tokens[0]); c == "CH" || c == "HS" || c == "CS" {
+				return nil, fmt.Errorf("line %d: unsupported class %s", e.line, tokens[0])
+			} else if ttl < 0 && tokens[0][0] >= '0' && tokens[0][0] <= '9' {
+				ttl, err = parseZonefileTTL(tokens[0])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", e.line, err)
+				}
+				tokens = tokens[1:]
+			} else {
+				break
+			}
+		}
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("line %d: incomplete record", e.line)
+		}
+		switch {
+		case ttl >= 0:
+			lastTTL = ttl
+		case directiveTTL >= 0:
+			ttl = directiveTTL
+		case lastTTL >= 0:
+			ttl = lastTTL
+		default:
+			ttl = defaultTTL
+		}
+
+		recordType := strings.ToUpper(tokens[0])
+		rdata := tokens[1:]
+		if recordType == "SOA" {
+			continue
+		}
+		if !supportedRecordTypes[recordType] {
+			return nil, fmt.Errorf("line %d: record type %s is not supported by deSEC", e.line, recordType)
+		}
+
+		subName, err := subNameFromQName(owner, domainName)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+
+		if i, ok := recordNameFields[recordType]; ok && i < len(rdata) {
+			rdata[i] = qualifyName(rdata[i], origin)
+		}
+		record := strings.Join(rdata, " ")
+
+		id := idFromNames(domainName, subName, recordType)
+		rrset, ok := result[id]
+		if !ok {
+			rrset = dsc.RRSet{
+				Domain:  domainName,
+				SubName: subName,
+				Type:    recordType,
+				TTL:     ttl,
+			}
+		} else if rrset.TTL != ttl {
+			return nil, fmt.Errorf("line %d: TTL %d differs from %d of other %s records at %s", e.line, ttl, rrset.TTL, recordType, owner)
+		}
+		rrset.Records = append(rrset.Records, record)
+		result[id] = rrset
+	}
+
+	for id, rrset := range result {
+		rrset.Records = normalizeRecordSet(rrset.Records)
+		result[id] = rrset
+	}
+	return result, nil
+}
+
+// tokenizeZonefile splits zone file text into entries, dropping comments and
+// joining records that span multiple lines in parentheses. Quoted strings
+// are kept as one token, including the quotes.
+func tokenizeZonefile(text string) ([]zonefileEntry, error) {
+	var entries []zonefileEntry
+	var current *zonefileEntry
+	depth := 0
+
+	line := 1
+	for i := 0; i < len(text); {
+		ch := text[i]
+		switch {
+		case ch == '\n':
+			line++
+			i++
+			if depth == 0 && current != nil {
+				entries = append(entries, *current)
+				current = nil
+			}
+			continue
+		case ch == ';':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+			continue
+		case ch == ' ' || ch == '\t' || ch == '\r':
+			i++
+			continue
+		case ch == '(':
+			depth++
+			i++
+			continue
+		case ch == ')':
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced parentheses", line)
+			}
+			depth--
+			i++
+			continue
+		}
+
+		if current == nil {
+			lineStart := strings.LastIndexByte(text[:i], '\n') + 1
+			current = &zonefileEntry{line: line, continued: i > lineStart}
+		}
+
+		start := i
+		if ch == '"' {
+			i++
+			for i < len(text) && text[i] != '"' {
+				if text[i] == '\\' {
+					i++
+				} else if text[i] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				i++
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			i++
+		} else {
+			for i < len(text) && !strings.ContainsRune(" \t\r\n;()\"", rune(text[i])) {
+				if text[i] == '\\' {
+					i++
+				}
+				i++
+			}
+		}
+		current.tokens = append(current.tokens, text[start:i])
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("line %d: unbalanced parentheses", line)
+	}
+	if current != nil {
+		entries = append(entries, *current)
+	}
+	return entries, nil
+}
+
+// qualifyName turns a name relative to origin into a fully qualified one.
+func qualifyName(name, origin string) string {
+	if name == "@" {
+		return origin
+	}
+	if strings.HasSuffix(name, ".") {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(name) + "." + origin
+}
+
+// parseZonefileTTL parses a TTL in seconds, or with BIND's unit suffixes such
+// as 1h30m.
+func parseZonefileTTL(s string) (int, error) {
+	if v, err := strconv.Atoi(s); err == nil {
+		return v, nil
+	}
+
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	total := 0
+	value := -1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= '0' && c <= '9' {
+			if value < 0 {
+				value = 0
+			}
+			value = value*10 + int(c-'0')
+			continue
+		}
+		unit, ok := units[c|0x20]
+		if !ok || value < 0 {
+			return 0, fmt.Errorf("invalid TTL %q", s)
+		}
+		total += value * unit
+		value = -1
+	}
+	if value >= 0 {
+		return 0, fmt.Errorf("invalid TTL %q", s)
+	}
+	return total, nil
+}
+
+// sortedRRSets returns RRsets sorted by id.
+func sortedRRSets(rrsets map[string]dsc.RRSet) []dsc.RRSet {
+	ids := make([]string, 0, len(rrsets))
+	for id := range rrsets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := make([]dsc.RRSet, len(ids))
+	for i, id := range ids {
+		result[i] = rrsets[id]
+	}
+	return result
+}
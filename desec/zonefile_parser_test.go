@@ -0,0 +1,88 @@
+package desec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseZonefile(t *testing.T) {
+	zonefile := `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	get.desec.io. get.desec.io. (
+		2024010101 ; serial
+		86400 3600 2419200 3600 )
+	IN	MX	10 mail
+	IN	MX	20 mail.elsewhere.example.
+@	300	IN	TXT	"v=spf1 include:_spf.example.net" " -all"
+www		CNAME	@
+mail	7200	A	192.0.2.1
+	7200	IN A 192.0.2.2 ; second address
+$ORIGIN sub.example.com.
+srv	IN	SRV	( 0 5 5060
+		sip )
+`
+
+	rrsets, err := parseZonefile(zonefile, "example.com", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]struct {
+		ttl     int
+		records []string
+	}{
+		"example.com/@/MX":        {3600, []string{"10 mail.example.com.", "20 mail.elsewhere.example."}},
+		"example.com/@/TXT":       {300, []string{"v=spf1 include:_spf.example.net -all"}},
+		"example.com/www/CNAME":   {3600, []string{"example.com."}},
+		"example.com/mail/A":      {7200, []string{"192.0.2.1", "192.0.2.2"}},
+		"example.com/srv.sub/SRV": {3600, []string{"0 5 5060 sip.sub.example.com."}},
+	}
+
+	if len(rrsets) != len(expected) {
+		t.Fatalf("expected %d RRsets, got %v", len(expected), rrsets)
+	}
+	for id, want := range expected {
+		got, ok := rrsets[id]
+		if !ok {
+			t.Errorf("missing RRset %s", id)
+			continue
+		}
+		if got.TTL != want.ttl {
+			t.Errorf("%s: expected TTL %d, got %d", id, want.ttl, got.TTL)
+		}
+		if !reflect.DeepEqual(got.Records, want.records) {
+			t.Errorf("%s: expected records %q, got %q", id, want.records, got.Records)
+		}
+	}
+}
+
+func TestParseZonefileLastTTL(t *testing.T) {
+	rrsets, err := parseZonefile("a 600 A 192.0.2.1\nb A 192.0.2.2\n", "example.com", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := rrsets["example.com/b/A"].TTL; ttl != 600 {
+		t.Errorf("expected TTL of previous record, got %d", ttl)
+	}
+}
+
+func TestParseZonefileErrors(t *testing.T) {
+	cases := map[string]string{
+		"unsupported type":  "@ 3600 IN NSEC3PARAM 1 0 0 -\n",
+		"outside of domain": "host.example.net. 3600 IN A 192.0.2.1\n",
+		"mixed TTLs":        "@ 3600 IN A 192.0.2.1\n@ 60 IN A 192.0.2.2\n",
+		"include":           "$INCLUDE other.zone\n",
+		"parentheses":       "@ 3600 IN MX ( 10 mail\n",
+		"bad TTL":           "@ 1x IN A 192.0.2.1\n",
+	}
+
+	for name, zonefile := range cases {
+		_, err := parseZonefile(zonefile, "example.com", 3600)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if !strings.HasPrefix(err.Error(), "line ") {
+			t.Errorf("%s: error without line number: %v", name, err)
+		}
+	}
+}